@@ -0,0 +1,9 @@
+package main
+
+// Built-in archetypes register themselves with internal/archetype on
+// import. New archetypes only need a line here.
+import (
+	_ "github.com/holodanger/genesis/internal/goservice"
+	_ "github.com/holodanger/genesis/internal/hybrid"
+	_ "github.com/holodanger/genesis/internal/t3"
+)
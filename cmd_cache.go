@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/cache"
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/render"
+)
+
+// cacheSources are the archetypes whose pinned dependencies a prepare
+// fetches, with the options that make them pin the most. hybrid pins the
+// same as t3 and go together.
+var cacheSources = []struct {
+	archetype string
+	opts      map[string]string
+}{
+	{"t3", nil},
+	{"go", map[string]string{"ai": "true"}},
+}
+
+func runCache(args []string) error {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "Usage:\n  genesis cache prepare")
+		fmt.Fprintln(os.Stderr, "\nManage the local dependency cache 'genesis new -offline' installs from.")
+	}
+	if len(args) == 0 {
+		usage()
+		return usagef("cache needs a subcommand")
+	}
+	switch args[0] {
+	case "prepare":
+		return runCachePrepare(args[1:])
+	case "-h", "-help", "--help", "help":
+		usage()
+		return flag.ErrHelp
+	default:
+		return usagef("unknown cache subcommand: '%s'", args[0])
+	}
+}
+
+func runCachePrepare(args []string) error {
+	fs := newFlagSet("cache prepare", "",
+		"Fetch the npm packages and Go modules the built-in archetypes pin into the local cache\n"+
+			"("+cache.EnvDir+", or ~/.cache/genesis), so 'genesis new -offline' can install from it.\n"+
+			"Needs the network, bun and go.")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("cache prepare takes no arguments")
+	}
+	dir := cache.Dir()
+	if dir == "" {
+		return fmt.Errorf("no cache directory: set %s", cache.EnvDir)
+	}
+
+	var plans []render.Plan
+	for _, src := range cacheSources {
+		arch, ok := archetype.Lookup(src.archetype)
+		if !ok {
+			return fmt.Errorf("archetype %s is not registered", src.archetype)
+		}
+		values, err := archetype.Resolve(arch, src.opts)
+		if err != nil {
+			return err
+		}
+		plan, err := arch.Plan(archetype.Project{Name: "genesis-cache", Options: values})
+		if err != nil {
+			return fmt.Errorf("render %s: %w", src.archetype, err)
+		}
+		plans = append(plans, plan)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = progress.With(ctx, printProgress)
+
+	fmt.Printf("🗄️  [CACHE] Preparing the offline cache in %s\n", dir)
+	pins, err := cache.Prepare(ctx, dir, plans)
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, p := range pins {
+		counts[p.Ecosystem]++
+	}
+	fmt.Printf("\n✅ [CACHE] Cached %d npm packages and %d Go modules.\n", counts[cache.NPM], counts[cache.Go])
+	fmt.Println("   'genesis new -offline' now installs from the cache.")
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/holodanger/genesis/internal/drift"
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/textdiff"
+	"github.com/holodanger/genesis/internal/version"
+)
+
+func runDiff(args []string) error {
+	fs := newFlagSet("diff", "[-dir <project>] [-u] [-changed] [path ...]",
+		"Compare a Genesis project with what the current templates produce for the\n"+
+			"same archetype and options. Read-only. Paths limit the report to those\n"+
+			"files or directories, e.g. 'genesis diff -u api/internal/server'.")
+	dir := fs.String("dir", ".", "Project root (the directory holding "+manifest.FileName+")")
+	unified := fs.Bool("u", false, "Print unified diffs for modified, deleted and added files")
+	changed := fs.Bool("changed", false, "Hide untouched files")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	root, err := filepath.Abs(*dir)
+	if err != nil {
+		return err
+	}
+	m, _, _, baseline, err := rerender(root)
+	if err != nil {
+		return err
+	}
+
+	entries, err := drift.Compare(root, baseline, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n🔍 [DIFF] %s vs Genesis %s templates (%s)\n", m.Name, version.Version, m.Archetype)
+	counts := map[drift.Status]int{}
+	for _, e := range entries {
+		counts[e.Status]++
+		if *changed && e.Status == drift.Untouched {
+			continue
+		}
+		fmt.Printf("    %-9s  %s\n", e.Status, e.Path)
+	}
+	fmt.Printf("\n   %d untouched, %d modified, %d deleted, %d added\n",
+		counts[drift.Untouched], counts[drift.Modified], counts[drift.Deleted], counts[drift.Added])
+
+	if *unified {
+		for _, e := range entries {
+			if e.Status == drift.Untouched {
+				continue
+			}
+			fmt.Println()
+			fmt.Print(textdiff.Unified("genesis/"+e.Path, "project/"+e.Path, e.Baseline, e.Current))
+		}
+	}
+	return nil
+}
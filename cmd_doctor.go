@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// tool is a binary Genesis (or the projects it generates) relies on.
+type tool struct {
+	name     string
+	args     []string // arguments that print the version; nil if it has none
+	required bool
+	purpose  string
+}
+
+var toolchain = []tool{
+	{name: "go", args: []string{"version"}, required: true, purpose: "go archetype, hybrid api"},
+	{name: "bun", args: []string{"--version"}, required: true, purpose: "t3 archetype, hybrid web"},
+	{name: "docker", args: []string{"--version"}, required: true, purpose: "local Postgres"},
+	{name: "git", args: []string{"--version"}, required: false, purpose: "version control"},
+	{name: "sqlc", args: []string{"version"}, required: false, purpose: "codegen hook of the go archetype and hybrid api"},
+	{name: "gofmt", required: false, purpose: "format hook of the go archetype and hybrid api"},
+}
+
+func runDoctor(args []string) error {
+	fs := newFlagSet("doctor", "", "Check that the toolchain used by generated projects is installed.")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("doctor takes no arguments")
+	}
+
+	fmt.Println("🩺 [DOCTOR] Scanning Toolchain...")
+	missing := 0
+	for _, t := range toolchain {
+		version, err := probe(t)
+		switch {
+		case err == nil:
+			fmt.Printf("   ✅ %-7s %s\n", t.name, version)
+		case t.required:
+			missing++
+			fmt.Printf("   ❌ %-7s missing (%s)\n", t.name, t.purpose)
+		default:
+			fmt.Printf("   ⚠️  %-7s missing (optional: %s)\n", t.name, t.purpose)
+		}
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%d required tool(s) missing", missing)
+	}
+	fmt.Println("\n✅ [DOCTOR] Toolchain operational.")
+	return nil
+}
+
+// probe locates t on PATH and returns the first line of its version
+// output, or where it was found if it cannot print a version.
+func probe(t tool) (string, error) {
+	path, err := exec.LookPath(t.name)
+	if err != nil {
+		return "", err
+	}
+	if t.args == nil {
+		return path, nil
+	}
+	out, err := exec.Command(path, t.args...).Output()
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if line == "" {
+		return "", errors.New("no version output")
+	}
+	return line, nil
+}
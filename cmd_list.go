@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/holodanger/genesis/internal/archetype"
+)
+
+func runList(args []string) error {
+	fs := newFlagSet("list", "", "List the archetypes Genesis can spawn, with their options.")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("list takes no arguments")
+	}
+
+	fmt.Println("⚔️  [GENESIS] Available Archetypes:")
+	for _, a := range archetype.All() {
+		fmt.Printf("\n   %-8s %s\n", a.Name(), a.Description())
+		for _, opt := range a.Options() {
+			fmt.Printf("     -opt %s=<%s>  %s", opt.Name, opt.Kind, opt.Usage)
+			if opt.Default != "" {
+				fmt.Printf(" (default %q)", opt.Default)
+			}
+			fmt.Println()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/cache"
+	"github.com/holodanger/genesis/internal/conflict"
+	"github.com/holodanger/genesis/internal/hook"
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/pack"
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/spec"
+	"github.com/holodanger/genesis/internal/stage"
+	"github.com/holodanger/genesis/internal/templates"
+	"github.com/holodanger/genesis/internal/vfs"
+	"golang.org/x/term"
+)
+
+func runNew(args []string) error {
+	// 1. TACTICAL INPUT
+	fs := newFlagSet("new", "{-name <project_name> [-type <archetype> | -template <dir>] [-ai] [-module <path>] [-opt key=value ...] | -f genesis.yaml} [-dry-run | -out <file>] [-force|-skip-existing] [-skip-install] [-offline] [-git] [-jobs N] [-no-input]",
+		"Spawn a new project from a registered archetype, a local template pack, or a genesis.yaml spec.\nWithout -name or -f, a terminal gets a wizard that asks for the rest.\nWith -out, the project is written to a .tar.gz or .zip instead and no commands run.\nSee 'genesis list' for archetypes and their options.")
+	projectName := fs.String("name", "", "Project Name")
+	projectType := fs.String("type", "t3", "Archetype: "+strings.Join(archetype.Names(), " | "))
+	templateDir := fs.String("template", "", "Spawn from the template pack in this directory instead of a built-in archetype")
+	aiEnabled := fs.Bool("ai", false, "Enable AI Features (OpenAI); shorthand for -opt ai=true")
+	modulePath := fs.String("module", "", "Go module path (e.g. github.com/org/project); shorthand for -opt module=<path>")
+	opts := optionFlag{}
+	fs.Var(opts, "opt", "Archetype option as key=value (repeatable)")
+	specPath := fs.String("f", "", "Read the project from a genesis.yaml spec instead of flags")
+	dryRun := fs.Bool("dry-run", false, "Render in memory and print the planned tree and commands; write nothing")
+	outPath := fs.String("out", "", "Write the project to this .tar.gz, .tgz or .zip archive instead of a directory; run no commands")
+	force := fs.Bool("force", false, "Overwrite existing files in the target directory")
+	skipExisting := fs.Bool("skip-existing", false, "Keep existing files in the target directory; only add new ones")
+	noInput := fs.Bool("no-input", false, "Never prompt: fail on missing input and refuse conflicts (for scripts)")
+	skipInstall := fs.Bool("skip-install", false, "Skip the install and tidy hooks after generation")
+	offline := fs.Bool("offline", false, "Install from the local cache ('genesis cache prepare') and skip other hooks that need the network")
+	jobs := fs.Int("jobs", 4, "Run the hooks of up to this many nodes at once")
+	gitInit := fs.Bool("git", false, "Make the project a git repository with an initial commit (skipped inside an existing work tree)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *force && *skipExisting {
+		return usagef("-force and -skip-existing are mutually exclusive")
+	}
+	if *outPath != "" {
+		if *dryRun {
+			return usagef("-dry-run and -out are mutually exclusive")
+		}
+		if *skipExisting {
+			return usagef("-skip-existing has no meaning with -out")
+		}
+		if _, err := archiveFormat(*outPath); err != nil {
+			return usagef("%v", err)
+		}
+	}
+
+	var (
+		arch     archetype.Archetype
+		values   archetype.Values
+		project  archetype.Project
+		specData []byte
+	)
+	if *specPath != "" {
+		// 1.1 DECLARED INPUT
+		// The spec is the single source of truth; mixing it with flags would
+		// make the committed genesis.yaml lie about how the project was made.
+		var clash []string
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name", "type", "template", "ai", "module", "opt":
+				clash = append(clash, "-"+f.Name)
+			}
+		})
+		if len(clash) > 0 || fs.NArg() > 0 {
+			return usagef("-f cannot be combined with: %s", strings.Join(append(clash, fs.Args()...), " "))
+		}
+
+		data, err := os.ReadFile(*specPath)
+		if err != nil {
+			return fmt.Errorf("read spec: %w", err)
+		}
+		s, err := spec.Parse(filepath.Base(*specPath), data)
+		if err != nil {
+			return usagef("invalid spec: %v", err)
+		}
+		if arch, values, err = resolveSpec(s); err != nil {
+			return err
+		}
+		*projectName = s.Name
+		specData = data
+	} else if *projectName == "" && fs.NArg() == 0 && !*noInput && isTerminal(os.Stdin) {
+		// 1.2 GUIDED INPUT
+		s, err := wizard(os.Stdin, os.Stdout)
+		if errors.Is(err, errWizardDeclined) {
+			fmt.Println("   Nothing was spawned.")
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("wizard: %w", err)
+		}
+		if arch, values, err = resolveSpec(s); err != nil {
+			return err
+		}
+		*projectName = s.Name
+	} else {
+		// Allow `genesis new <name>` as well as `genesis new -name <name>`.
+		if *projectName == "" && fs.NArg() == 1 {
+			*projectName = fs.Arg(0)
+		} else if fs.NArg() > 0 {
+			return usagef("unexpected arguments: %v", fs.Args())
+		}
+
+		if *projectName == "" {
+			return usagef("genesis new -name <project_name> -type <%s> -ai=<true|false>", strings.Join(archetype.Names(), "|"))
+		}
+
+		if *templateDir != "" {
+			typeSet := false
+			fs.Visit(func(f *flag.Flag) { typeSet = typeSet || f.Name == "type" })
+			if typeSet {
+				return usagef("-type and -template are mutually exclusive")
+			}
+			pk, err := pack.Load(*templateDir)
+			if err != nil {
+				return usagef("%v", err)
+			}
+			arch = pk
+		} else {
+			var ok bool
+			arch, ok = archetype.Lookup(*projectType)
+			if !ok {
+				return usagef("unknown archetype: '%s'. Options: %s", *projectType, strings.Join(archetype.Names(), ", "))
+			}
+		}
+
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "ai":
+				opts["ai"] = strconv.FormatBool(*aiEnabled)
+			case "module":
+				opts["module"] = *modulePath
+			}
+		})
+		var err error
+		values, err = archetype.Resolve(arch, opts)
+		if err != nil {
+			return usagef("%v", err)
+		}
+	}
+
+	// 1.3 IDENTITY CHECK
+	// The name becomes a directory, an npm package, a Go module, a database
+	// and a container; it has to be valid as all of them.
+	if err := archetype.ValidateName(*projectName); err != nil {
+		return usagef("%v", err)
+	}
+
+	// 2. ROOT ESTABLISHMENT
+	// We establish the root path here, but the specific builders
+	// handle their internal file structures.
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("resolve working directory: %w", err)
+	}
+	project = archetype.Project{
+		Name:    *projectName,
+		Root:    filepath.Join(currentDir, *projectName),
+		Options: values,
+	}
+
+	plan, err := arch.Plan(project)
+	if err != nil {
+		return fmt.Errorf("render failed: %w", err)
+	}
+
+	// 2.0 PROVENANCE
+	// Every project carries a record of the Genesis, archetype and options
+	// that produced it, and a snapshot of every file as generated.
+	stamps, err := stamp(&plan, arch, project)
+	if err != nil {
+		return fmt.Errorf("manifest failed: %w", err)
+	}
+	if *gitInit {
+		// Last, so the first commit holds what the other hooks produced.
+		msg := fmt.Sprintf("chore: scaffold %s from the %s archetype", project.Name, arch.Name())
+		plan.Commands = append(plan.Commands, hook.GitInit(msg)...)
+	}
+	if *offline && !*skipInstall && *outPath == "" {
+		// Installs come from the local cache instead, and only if it holds
+		// every pinned version: better to refuse now than to leave a
+		// project half installed. The lockfiles prepare resolved go in
+		// with the project, so bun installs exactly what is cached.
+		var locks []render.File
+		if plan.Commands, locks, err = cache.Offline(cache.Dir(), plan); err != nil {
+			return err
+		}
+		for _, f := range locks {
+			plan.Set(f.Path, f.Content)
+			stamps = append(stamps, f)
+		}
+		plan.Sort()
+	}
+	if specData != nil {
+		// The spec travels with the project, verbatim, so it can be
+		// re-run or reviewed later.
+		plan.Set(manifest.SpecName, specData)
+		plan.Sort()
+		stamps = append(stamps, render.File{Path: manifest.SpecName, Content: specData, Mode: 0644})
+	}
+	// The builders report progress through the context and the terminal
+	// shows it as it happens. Ctrl-C cancels whatever is running: a render,
+	// a hook.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = progress.With(ctx, printProgress)
+	if *dryRun {
+		// The real generation runs, silently and into memory: what is
+		// printed is what would be written, checked the same way a spawn is.
+		ctx = progress.With(ctx, nil)
+		mem := vfs.NewMem()
+		if err := forge(ctx, arch, project, render.NewWriter(project.Root, mem, nil), stamps); err != nil {
+			return err
+		}
+		if err := stage.Verify(mem, plan, nil); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+		printPlan(project.Name, project.Root, memPlan(mem, plan.Commands))
+		return nil
+	}
+
+	if *outPath != "" {
+		// 2.1 EXPORT
+		// The project goes into an archive; the working directory is never
+		// touched and nothing is installed.
+		return exportArchive(ctx, *outPath, *force, arch, project, plan, stamps)
+	}
+
+	// 2.2 TERRITORY CHECK
+	// Never clobber what someone has already edited unless told to.
+	conflicts, nonEmpty, err := conflict.Detect(project.Root, plan.Files)
+	if err != nil {
+		return fmt.Errorf("failed to survey territory: %w", err)
+	}
+	keep, err := conflict.Resolve(conflictPolicy(*force, *skipExisting, *noInput), conflicts, nonEmpty, os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n⚔️  [GENESIS] Spawning Archetype: %s | Node: %s | AI: %v\n", arch.Name(), project.Name, values.Bool("ai"))
+	if dir := templates.Dir(); dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			fmt.Printf("🎨 [TEMPLATES] Applying overrides from %s\n", dir)
+		}
+	}
+
+	// 3. STRATEGY EXECUTION
+	// Render into a stage next to the target; only a validated result is
+	// moved into place, so a failure leaves nothing behind.
+	st, err := stage.Begin(project.Root)
+	if err != nil {
+		return fmt.Errorf("failed to secure territory: %w", err)
+	}
+	defer st.Rollback()
+
+	w := st.Writer(keep)
+	if err := forge(ctx, arch, project, w, stamps); err != nil {
+		return err
+	}
+	if err := st.Validate(plan, keep); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	if err := st.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	for _, path := range w.Kept {
+		fmt.Printf("    ├── Kept: %s (existing)\n", path)
+	}
+
+	// 4. POST-GENERATION HOOKS
+	// The project is in place; a failing hook is reported, not fatal.
+	if len(plan.Commands) > 0 {
+		fmt.Println("\n🪝 [HOOKS] Running post-generation hooks...")
+		policy := hook.Policy{
+			SkipInstall: *skipInstall,
+			Offline:     *offline,
+			InWorkTree:  *gitInit && hook.InWorkTree(ctx, project.Root),
+			Jobs:        *jobs,
+		}
+		results := hook.Run(ctx, project.Root, plan.Commands, policy)
+		printHookSummary(results)
+		if err := hook.Err(results); err != nil {
+			fmt.Printf("⚠️  [WARN] Some hooks failed; re-run them by hand:\n%v\n", err)
+		}
+	}
+
+	// 5. DEBRIEF
+	printDebrief(project.Name, arch.Launch())
+	return nil
+}
+
+// forge runs the archetype's generation into w and adds the provenance
+// stamps.
+func forge(ctx context.Context, arch archetype.Archetype, project archetype.Project, w *render.Writer, stamps []render.File) error {
+	if err := arch.Generate(ctx, project, w); err != nil {
+		return fmt.Errorf("forge failed: %w", err)
+	}
+	for _, f := range stamps {
+		if err := w.WriteFile(filepath.Join(project.Root, f.Path), f.Content, f.Mode); err != nil {
+			return fmt.Errorf("manifest failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveSpec turns a spec into an archetype and its option values.
+func resolveSpec(s *spec.Spec) (archetype.Archetype, archetype.Values, error) {
+	if err := archetype.ValidateName(s.Name); err != nil {
+		return nil, nil, usagef("%s:%d: %v", s.File(), s.Line("name"), err)
+	}
+	arch, ok := archetype.Lookup(s.Archetype)
+	if !ok {
+		return nil, nil, usagef("%s:%d: unknown archetype: '%s'. Options: %s", s.File(), s.Line("archetype"), s.Archetype, strings.Join(archetype.Names(), ", "))
+	}
+	values, err := s.Values(arch)
+	if err != nil {
+		return nil, nil, usagef("invalid spec: %v", err)
+	}
+	return arch, values, nil
+}
+
+// conflictPolicy picks how existing files are treated: explicit flags win,
+// otherwise a terminal gets asked and everything else is refused.
+func conflictPolicy(force, skipExisting, noInput bool) conflict.Policy {
+	switch {
+	case force:
+		return conflict.Force
+	case skipExisting:
+		return conflict.SkipExisting
+	case !noInput && isTerminal(os.Stdin):
+		return conflict.Ask
+	default:
+		return conflict.Refuse
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal. A character
+// device is not enough: /dev/null is one too.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// optionFlag collects repeated -opt key=value flags.
+type optionFlag map[string]string
+
+func (o optionFlag) String() string {
+	var pairs []string
+	for k, v := range o {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (o optionFlag) Set(s string) error {
+	key, val, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	o[key] = val
+	return nil
+}
+
+// printHookSummary tabulates what became of each hook.
+func printHookSummary(results []hook.Result) {
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   HOOK\tDIR\tCOMMAND\tSTATUS\tTIME\tNOTE")
+	for _, r := range results {
+		dir, elapsed := r.Command.Dir, "-"
+		if dir == "" {
+			dir = "."
+		}
+		if r.Status != hook.Skipped {
+			elapsed = r.Elapsed.Round(100 * time.Millisecond).String()
+		}
+		fmt.Fprintf(tw, "   %s\t%s\t%s\t%s\t%s\t%s\n", r.Command.Hook, dir, r.Command, r.Status, elapsed, r.Reason)
+	}
+	tw.Flush()
+}
+
+// printMu keeps lines from nodes that run side by side whole.
+var printMu sync.Mutex
+
+// printProgress shows a generation's events in the terminal. Nodes run
+// concurrently, so command output is prefixed with the node it came from.
+func printProgress(e progress.Event) {
+	printMu.Lock()
+	defer printMu.Unlock()
+
+	indent, tag := "  ", ""
+	if e.Node != "" {
+		indent, tag = "    ", "["+e.Node+"] "
+	}
+	switch e.Kind {
+	case progress.Step:
+		fmt.Printf("%s%s\n", indent, e.Msg)
+	case progress.File:
+		fmt.Printf("    ├── Injected: %s\n", path.Join(e.Node, e.Path))
+	case progress.Command:
+		fmt.Printf("    ⚙️  %s%s\n", tag, e.Msg)
+	case progress.Output:
+		fmt.Printf("%s%s\n", tag, e.Msg)
+	}
+}
+
+func printDebrief(name, runCmd string) {
+	fmt.Printf("\n✅ [SUCCESS] Node '%s' is operational.\n", name)
+	fmt.Println("   -------------------------------------")
+	fmt.Printf("   cd %s\n", name)
+	fmt.Printf("   %s\n", runCmd)
+	fmt.Println("   -------------------------------------")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/lint"
+	"github.com/holodanger/genesis/internal/pack"
+	"github.com/holodanger/genesis/internal/templates"
+)
+
+func runTemplates(args []string) error {
+	usage := func() {
+		fmt.Fprintln(os.Stderr, "Usage:\n  genesis templates lint [pack_dir ...]")
+		fmt.Fprintln(os.Stderr, "\nWork with the template trees Genesis renders from.")
+	}
+	if len(args) == 0 {
+		usage()
+		return usagef("templates needs a subcommand")
+	}
+	switch args[0] {
+	case "lint":
+		return runTemplatesLint(args[1:])
+	case "-h", "-help", "--help", "help":
+		usage()
+		return flag.ErrHelp
+	default:
+		return usagef("unknown templates subcommand: '%s'", args[0])
+	}
+}
+
+func runTemplatesLint(args []string) error {
+	fs := newFlagSet("templates lint", "[pack_dir ...]",
+		"Parse every built-in and override template, check that the fields they reference exist in\n"+
+			"each archetype's data model, and flag templates that are never rendered or are shadowed by\n"+
+			"an override. Template packs given as arguments are linted too.")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	var targets []archetype.Archetype
+	targets = append(targets, archetype.All()...)
+	for _, dir := range fs.Args() {
+		pk, err := pack.Load(dir)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		targets = append(targets, pk)
+	}
+
+	fmt.Println("🔍 [LINT] Scanning templates...")
+	if dir := templates.Dir(); dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			fmt.Printf("   Overrides: %s\n", dir)
+		}
+	}
+
+	errs, warnings := 0, 0
+	for _, a := range targets {
+		t, ok := a.(archetype.Templated)
+		if !ok {
+			continue
+		}
+		paths, data := t.TemplateSet()
+		findings := lint.Check(t.Templates(), paths, data)
+
+		label := a.Name()
+		if pk, ok := a.(*pack.Pack); ok {
+			label += " (" + pk.Dir + ")"
+		}
+		if len(findings) == 0 {
+			fmt.Printf("\n   ✅ %s: %d templates\n", label, len(paths))
+			continue
+		}
+		fmt.Printf("\n   %s: %d templates\n", label, len(paths))
+		for _, f := range findings {
+			mark := "⚠️ "
+			if f.Severity == lint.Error {
+				mark = "❌"
+				errs++
+			} else {
+				warnings++
+			}
+			fmt.Printf("     %s %s\n", mark, f)
+		}
+	}
+
+	// An override directory named after no archetype is never read.
+	if dir := templates.Dir(); dir != "" {
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if _, ok := archetype.Lookup(e.Name()); !ok && e.IsDir() {
+				fmt.Printf("\n   ⚠️  %s: override directory matches no archetype\n", filepath.Join(dir, e.Name()))
+				warnings++
+			}
+		}
+	}
+
+	fmt.Printf("\n   %d error(s), %d warning(s)\n", errs, warnings)
+	if errs > 0 {
+		return errors.New("template lint failed")
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/pack"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/stage"
+	"github.com/holodanger/genesis/internal/textdiff"
+	"github.com/holodanger/genesis/internal/upgrade"
+	"github.com/holodanger/genesis/internal/version"
+)
+
+func runUpgrade(args []string) error {
+	fs := newFlagSet("upgrade", "[-dir <project>] [-dry-run] [-rej]",
+		"Re-render a Genesis project with the current templates and three-way merge\n"+
+			"the result into your edits, using the manifest recorded at generation.")
+	dir := fs.String("dir", ".", "Project root (the directory holding "+manifest.FileName+")")
+	dryRun := fs.Bool("dry-run", false, "Report what would change; write nothing")
+	rej := fs.Bool("rej", false, "Leave colliding files as they are and write the template change to <file>.rej")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("upgrade takes no arguments")
+	}
+
+	root, err := filepath.Abs(*dir)
+	if err != nil {
+		return err
+	}
+
+	// 1. RECALL: what was generated, and what would be generated today.
+	m, arch, project, next, err := rerender(root)
+	if err != nil {
+		return err
+	}
+	base, err := upgrade.RecoverBase(root, m, next)
+	if err != nil {
+		return fmt.Errorf("recover merge base: %w", err)
+	}
+
+	fmt.Printf("\n⚔️  [UPGRADE] %s | Archetype: %s | Genesis %s → %s\n", m.Name, m.Archetype, m.Genesis, version.Version)
+
+	// 2. RECONCILE
+	changes, err := upgrade.Plan(upgrade.Input{
+		Root:     root,
+		Manifest: m,
+		Base:     base,
+		Next:     next,
+		Labels: textdiff.Labels{
+			Ours:   "yours",
+			Base:   "genesis " + m.Genesis,
+			Theirs: "genesis " + version.Version,
+		},
+		Rej: *rej,
+	})
+	if err != nil {
+		return err
+	}
+
+	unresolved := 0
+	for _, c := range changes {
+		if c.Action == upgrade.Unchanged {
+			continue
+		}
+		fmt.Printf("    ├── %-9s %s\n", c.Action, c.Path)
+		if c.Action == upgrade.Conflict || c.Action == upgrade.Rejected {
+			unresolved++
+		}
+	}
+	if *dryRun {
+		fmt.Println("\n   Nothing was written. Re-run without -dry-run to upgrade.")
+		return nil
+	}
+
+	// 3. APPLY through a stage so a failure leaves the project untouched.
+	if err := applyUpgrade(root, arch, project, next, changes); err != nil {
+		return err
+	}
+
+	if unresolved > 0 {
+		return fmt.Errorf("upgrade left %d file(s) to resolve by hand (conflict markers or %s files)", unresolved, upgrade.RejectSuffix)
+	}
+	fmt.Printf("\n✅ [SUCCESS] Node '%s' upgraded to Genesis %s.\n", m.Name, version.Version)
+	return nil
+}
+
+// rerender loads the manifest at root and renders the same archetype and
+// options with the current templates.
+func rerender(root string) (*manifest.Manifest, archetype.Archetype, archetype.Project, render.Plan, error) {
+	var project archetype.Project
+
+	m, err := manifest.Load(root)
+	if err != nil {
+		return nil, nil, project, render.Plan{}, err
+	}
+	arch, err := manifestArchetype(m)
+	if err != nil {
+		return nil, nil, project, render.Plan{}, err
+	}
+	values, err := archetype.Resolve(arch, m.Options)
+	if err != nil {
+		return nil, nil, project, render.Plan{}, fmt.Errorf("manifest options: %w", err)
+	}
+
+	project = archetype.Project{Name: m.Name, Root: root, Options: values}
+	next, err := arch.Plan(project)
+	if err != nil {
+		return nil, nil, project, render.Plan{}, fmt.Errorf("render failed: %w", err)
+	}
+	return m, arch, project, next, nil
+}
+
+// manifestArchetype finds the archetype that generated m: a registered one,
+// or the template pack it recorded.
+func manifestArchetype(m *manifest.Manifest) (archetype.Archetype, error) {
+	if m.Template != "" {
+		pk, err := pack.Load(m.Template)
+		if err != nil {
+			return nil, fmt.Errorf("manifest template pack: %w", err)
+		}
+		return pk, nil
+	}
+	arch, ok := archetype.Lookup(m.Archetype)
+	if !ok {
+		return nil, fmt.Errorf("manifest names unknown archetype %q", m.Archetype)
+	}
+	return arch, nil
+}
+
+func applyUpgrade(root string, arch archetype.Archetype, project archetype.Project, next render.Plan, changes []upgrade.Change) error {
+	st, err := stage.Begin(root)
+	if err != nil {
+		return err
+	}
+	defer st.Rollback()
+	w := st.Writer(nil)
+
+	// Files keep the mode the templates give them, so scripts stay
+	// executable across upgrades.
+	modes := map[string]fs.FileMode{}
+	for _, f := range next.Files {
+		modes[f.Path] = f.Mode
+	}
+
+	var removals []string
+	for _, c := range changes {
+		if c.Content != nil {
+			mode, ok := modes[c.Path]
+			if !ok || mode == 0 {
+				mode = 0644
+			}
+			if err := w.WriteFile(filepath.Join(root, c.Path), c.Content, mode); err != nil {
+				return err
+			}
+		}
+		if c.Reject != nil {
+			if err := w.WriteFile(filepath.Join(root, c.Path+upgrade.RejectSuffix), c.Reject, 0644); err != nil {
+				return err
+			}
+		}
+		if c.Action == upgrade.Removed {
+			removals = append(removals, c.Path)
+		}
+	}
+
+	// The new generation becomes the baseline for the next upgrade.
+	stamps, err := stamp(&next, arch, project)
+	if err != nil {
+		return fmt.Errorf("manifest failed: %w", err)
+	}
+	for _, f := range stamps {
+		if err := w.WriteFile(filepath.Join(root, f.Path), f.Content, f.Mode); err != nil {
+			return err
+		}
+	}
+
+	if err := st.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	var errs []error
+	for _, p := range removals {
+		if err := os.Remove(filepath.Join(root, filepath.FromSlash(p))); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/vfs"
+)
+
+// printPlan reports what a generation would do: the file tree with sizes
+// and the external commands that would run afterwards.
+func printPlan(name, root string, plan render.Plan) {
+	fmt.Printf("\n🔍 [DRY-RUN] Planned territory: %s\n", root)
+
+	tree := &treeNode{children: map[string]*treeNode{}}
+	var total int
+	for _, f := range plan.Files {
+		tree.insert(strings.Split(f.Path, "/"), len(f.Content))
+		total += len(f.Content)
+	}
+
+	fmt.Printf("   %s/\n", name)
+	tree.print("   ")
+	fmt.Printf("\n   %d files, %s\n", len(plan.Files), formatSize(total))
+
+	if len(plan.Commands) > 0 {
+		fmt.Println("\n   Hooks:")
+		for _, c := range plan.Commands {
+			fmt.Printf("   %-8s $ (%s) %s\n", c.Hook, path.Join(name, c.Dir), c)
+		}
+	}
+	fmt.Println("\n   Nothing was written. Re-run without -dry-run to spawn.")
+}
+
+// memPlan is the plan of what was actually generated into mem, with the
+// commands that would follow.
+func memPlan(mem *vfs.Mem, commands []render.Command) render.Plan {
+	plan := render.Plan{Commands: commands}
+	for _, name := range mem.Paths() {
+		data, _ := fs.ReadFile(mem, name)
+		info, _ := fs.Stat(mem, name)
+		plan.Files = append(plan.Files, render.File{Path: name, Content: data, Mode: info.Mode()})
+	}
+	return plan
+}
+
+// treeNode is a directory (children != nil) or a file in the planned tree.
+type treeNode struct {
+	size     int
+	children map[string]*treeNode
+}
+
+func (n *treeNode) insert(parts []string, size int) {
+	if len(parts) == 1 {
+		n.children[parts[0]] = &treeNode{size: size}
+		return
+	}
+	child, ok := n.children[parts[0]]
+	if !ok {
+		child = &treeNode{children: map[string]*treeNode{}}
+		n.children[parts[0]] = child
+	}
+	child.insert(parts[1:], size)
+}
+
+func (n *treeNode) print(indent string) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		child := n.children[name]
+		branch, next := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		if child.children != nil {
+			fmt.Printf("%s%s%s/\n", indent, branch, name)
+			child.print(indent + next)
+			continue
+		}
+		fmt.Printf("%s%s%s (%s)\n", indent, branch, name, formatSize(child.size))
+	}
+}
+
+func formatSize(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
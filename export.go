@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/stage"
+	"github.com/holodanger/genesis/internal/vfs"
+)
+
+// archiveFormat picks the archive type from the output file name.
+func archiveFormat(name string) (string, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz", nil
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip", nil
+	}
+	return "", fmt.Errorf("-out %s: the archive must end in .tar.gz, .tgz or .zip", name)
+}
+
+// exportArchive generates the project in memory, validates it and writes
+// it to the archive at out under a top-level directory named after the
+// project. A failed export leaves no partial archive behind.
+func exportArchive(ctx context.Context, out string, force bool, arch archetype.Archetype, project archetype.Project, plan render.Plan, stamps []render.File) (err error) {
+	format, err := archiveFormat(out)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	if _, statErr := os.Stat(out); statErr == nil && !force {
+		return usagef("%s already exists; pass -force to replace it", out)
+	}
+
+	fmt.Printf("\n📦 [EXPORT] Archiving Archetype: %s | Node: %s | Format: %s\n", arch.Name(), project.Name, format)
+	mem := vfs.NewMem()
+	if err := forge(ctx, arch, project, render.NewWriter(project.Root, mem, nil), stamps); err != nil {
+		return err
+	}
+	if err := stage.Verify(mem, plan, nil); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(out)
+		}
+	}()
+
+	var a *vfs.Archive
+	if format == "zip" {
+		a = vfs.NewZip(f)
+	} else {
+		a = vfs.NewTarGz(f)
+	}
+	size, err := vfs.Copy(vfs.Sub(a, project.Name), mem)
+	if err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	if err := a.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	// DEBRIEF
+	fmt.Printf("\n✅ [SUCCESS] Node '%s' archived to %s (%d files, %s).\n", project.Name, out, len(mem.Paths()), formatSize(size))
+	fmt.Println("   -------------------------------------")
+	if format == "zip" {
+		fmt.Printf("   unzip %s\n", out)
+	} else {
+		fmt.Printf("   tar -xzf %s\n", out)
+	}
+	for _, c := range plan.Commands {
+		fmt.Printf("   (cd %s && %s)\n", path.Join(project.Name, c.Dir), c)
+	}
+	fmt.Printf("   cd %s\n", project.Name)
+	fmt.Printf("   %s\n", arch.Launch())
+	fmt.Println("   -------------------------------------")
+	return nil
+}
@@ -1,3 +1,12 @@
 module github.com/holodanger/genesis
 
-go 1.23.0
\ No newline at end of file
+go 1.23.0
+
+require (
+	golang.org/x/mod v0.21.0
+	golang.org/x/sync v0.8.0
+	golang.org/x/term v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.35.0 // indirect
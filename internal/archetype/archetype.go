@@ -0,0 +1,83 @@
+package archetype
+
+import (
+	"context"
+	"io/fs"
+	"strconv"
+
+	"github.com/holodanger/genesis/internal/render"
+)
+
+// Kind is the value type of an archetype option.
+type Kind string
+
+const (
+	KindBool   Kind = "bool"
+	KindString Kind = "string"
+	KindInt    Kind = "int"
+)
+
+// Option describes one knob an archetype exposes. The schema is used both
+// to validate user input and to describe the archetype in `genesis list`.
+type Option struct {
+	Name    string
+	Kind    Kind
+	Default string
+	Usage   string
+	Check   func(value string) error // optional extra validation
+}
+
+// Values holds resolved option values keyed by Option.Name.
+type Values map[string]string
+
+// Bool reports the boolean value of option name (false if unset or invalid).
+func (v Values) Bool(name string) bool {
+	b, _ := strconv.ParseBool(v[name])
+	return b
+}
+
+// Int reports the integer value of option name (0 if unset or invalid).
+func (v Values) Int(name string) int {
+	n, _ := strconv.Atoi(v[name])
+	return n
+}
+
+// String returns the raw value of option name.
+func (v Values) String(name string) string {
+	return v[name]
+}
+
+// Project is the input to an archetype: what to call it, where to put it,
+// and the resolved options.
+type Project struct {
+	Name    string
+	Root    string
+	Options Values
+}
+
+// Archetype is a project blueprint Genesis can spawn.
+type Archetype interface {
+	// Name is the identifier used on the command line (-type).
+	Name() string
+	// Description is a one-line summary shown by `genesis list`.
+	Description() string
+	// Options is the schema of options this archetype accepts.
+	Options() []Option
+	// Launch is the command that starts the spawned project, shown in the debrief.
+	Launch() string
+	// Plan renders the project in memory without touching disk. Its
+	// commands are the post-generation hooks, in the order they run.
+	Plan(p Project) (render.Plan, error)
+	// Generate writes the project files into p.Root through w.
+	Generate(ctx context.Context, p Project, w *render.Writer) error
+}
+
+// Templated is implemented by archetypes that render from a template tree,
+// so `genesis templates lint` can check them without generating anything.
+type Templated interface {
+	// Templates is the template tree, user overrides included.
+	Templates() fs.FS
+	// TemplateSet lists every output path the archetype may render, under
+	// any options, and a sample of the data its templates execute against.
+	TemplateSet() (paths []string, data any)
+}
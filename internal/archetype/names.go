@@ -0,0 +1,152 @@
+package archetype
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NameForms are the forms of the project name each ecosystem accepts. The raw
+// name is still used for the directory and for display.
+type NameForms struct {
+	NPM    string // npm package name: lowercase kebab ("my-app")
+	Snake  string // SQL identifiers: lowercase snake ("my_app")
+	Module string // default Go module path ("my-app")
+	DNS    string // Docker container and host names: DNS label ("my-app")
+}
+
+// maxNameLen keeps every derived form inside the tightest limit it is
+// used under (Postgres identifiers, 63 bytes, with room for suffixes).
+const maxNameLen = 50
+
+// maxIdentLen is the longest Postgres identifier (NAMEDATALEN - 1).
+const maxIdentLen = 63
+
+// sqlWords are SQL reserved words and the databases every Postgres
+// server already has.
+const sqlWords = "all and any array as asc between both by case cast check collate column constraint create current_date current_user default desc distinct do else end except false fetch for foreign from grant group having in initially intersect into leading limit localtime not null offset on only or order placing primary public references returning select session_user some symmetric table then to trailing true union unique user using variadic when where window with postgres template0 template1"
+
+// reserved are names that break at least one target once derived: Go
+// keywords and special module paths, SQL reserved words, npm blacklist
+// entries and Windows device names.
+var reserved = map[string]string{}
+
+func init() {
+	for _, list := range []struct{ target, words string }{
+		{"Go", "break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var main internal vendor std cmd"},
+		{"SQL", sqlWords},
+		{"npm", "node_modules favicon.ico"},
+		{"Windows", "con prn aux nul com1 com2 com3 com4 lpt1 lpt2 lpt3"},
+	} {
+		for _, w := range strings.Fields(list.words) {
+			if _, dup := reserved[w]; !dup {
+				reserved[w] = list.target
+			}
+		}
+	}
+}
+
+// ValidateName checks that name can be a directory and that every derived
+// form is legal and not a reserved word.
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("project name is required")
+	case len(name) > maxNameLen:
+		return fmt.Errorf("project name %q is longer than %d characters", name, maxNameLen)
+	case !isLetter(rune(name[0])):
+		return fmt.Errorf("project name %q must start with a letter", name)
+	}
+	for _, r := range name {
+		if !isLetter(r) && !isDigit(r) && !strings.ContainsRune("-_.", r) {
+			return fmt.Errorf("project name %q contains %q; use letters, digits, '-', '_' or '.' (for example %q)", name, r, DeriveNames(name).NPM)
+		}
+	}
+	if last := name[len(name)-1]; last == '-' || last == '.' || last == '_' {
+		return fmt.Errorf("project name %q must end with a letter or digit", name)
+	}
+
+	n := DeriveNames(name)
+	for _, form := range []string{strings.ToLower(name), n.NPM, n.Snake} {
+		if target, ok := reserved[form]; ok {
+			return fmt.Errorf("project name %q is reserved in %s; pick another name", name, target)
+		}
+	}
+	return nil
+}
+
+// CheckDBName accepts a Postgres database name that needs no quoting:
+// a lowercase letter, then lowercase letters, digits or '_', and not a
+// reserved word. It lands unquoted in DATABASE_URL and POSTGRES_DB.
+func CheckDBName(name string) error {
+	switch {
+	case len(name) > maxIdentLen:
+		return fmt.Errorf("database name %q is longer than %d characters", name, maxIdentLen)
+	case name == "" || name[0] < 'a' || name[0] > 'z':
+		return fmt.Errorf("database name %q must start with a lowercase letter", name)
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && !isDigit(r) && r != '_' {
+			return fmt.Errorf("database name %q contains %q; use lowercase letters, digits or '_'", name, r)
+		}
+	}
+	for _, w := range strings.Fields(sqlWords) {
+		if name == w {
+			return fmt.Errorf("database name %q is reserved in SQL; pick another name", name)
+		}
+	}
+	return nil
+}
+
+// DeriveNames splits name into words (on separators and case changes) and
+// joins them as each target expects.
+func DeriveNames(name string) NameForms {
+	words := splitWords(name)
+	kebab := strings.Join(words, "-")
+	return NameForms{
+		NPM:    kebab,
+		Snake:  strings.Join(words, "_"),
+		Module: kebab,
+		DNS:    kebab,
+	}
+}
+
+// splitWords lowercases name and breaks it into words: "MyHTTPApp_v2"
+// becomes [my http app v2].
+func splitWords(name string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		if !isLetter(r) && !isDigit(r) {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && len(cur) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || isDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
+
+// isDigit accepts ASCII digits only; every target is ASCII.
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isLetter accepts ASCII letters only; every target is ASCII.
+func isLetter(r rune) bool {
+	return r < unicode.MaxASCII && unicode.IsLetter(r)
+}
@@ -0,0 +1,76 @@
+package archetype
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeriveNames(t *testing.T) {
+	cases := []struct {
+		name       string
+		npm, snake string
+	}{
+		{"myapp", "myapp", "myapp"},
+		{"My-App", "my-app", "my_app"},
+		{"MyHTTPApp_v2", "my-http-app-v2", "my_http_app_v2"},
+		{"capstone.erp", "capstone-erp", "capstone_erp"},
+	}
+	for _, tc := range cases {
+		n := DeriveNames(tc.name)
+		if n.NPM != tc.npm || n.Snake != tc.snake || n.DNS != tc.npm || n.Module != tc.npm {
+			t.Errorf("DeriveNames(%q) = %+v, want npm %q snake %q", tc.name, n, tc.npm, tc.snake)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, ok := range []string{"myapp", "My-App", "erp_2025", "a"} {
+		if err := ValidateName(ok); err != nil {
+			t.Errorf("ValidateName(%q) = %v, want nil", ok, err)
+		}
+	}
+	bad := map[string]string{
+		"":             "required",
+		"my app":       "contains ' '",
+		"2fast":        "start with a letter",
+		"app-":         "end with a letter or digit",
+		"User":         "reserved in SQL",
+		"main":         "reserved in Go",
+		"../escape":    "start with a letter",
+		"a/b":          "contains '/'",
+		"Select":       "reserved",
+		"node_modules": "reserved in npm",
+		"app\u0661":    "contains '\u0661'",
+	}
+	for name, want := range bad {
+		err := ValidateName(name)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ValidateName(%q) = %v, want error containing %q", name, err, want)
+		}
+	}
+}
+
+func TestCheckDBName(t *testing.T) {
+	for _, ok := range []string{"my_app", "erp2025", "a"} {
+		if err := CheckDBName(ok); err != nil {
+			t.Errorf("CheckDBName(%q) = %v, want nil", ok, err)
+		}
+	}
+	bad := map[string]string{
+		"":                      "start with a lowercase letter",
+		"My_App":                "start with a lowercase letter",
+		"_app":                  "start with a lowercase letter",
+		`my db"x`:               "contains ' '",
+		"my-app":                "contains '-'",
+		"app;drop":              "contains ';'",
+		"user":                  "reserved in SQL",
+		"postgres":              "reserved in SQL",
+		strings.Repeat("a", 64): "longer than 63",
+	}
+	for name, want := range bad {
+		err := CheckDBName(name)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckDBName(%q) = %v, want error containing %q", name, err, want)
+		}
+	}
+}
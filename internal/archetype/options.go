@@ -0,0 +1,178 @@
+package archetype
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// ProjectOptions is the one data model every built-in template renders
+// against, whichever archetype or node it belongs to. It has no feature
+// modules: no archetype has optional modules to switch on yet.
+type ProjectOptions struct {
+	Name     string    // project name as given on the command line
+	Names    NameForms // the name in each ecosystem's form
+	Module   string    // Go module path
+	Hybrid   bool      // rendered as a node of a hybrid project
+	Ports    Ports
+	Database Database
+	Auth     Auth
+	AI       AI
+}
+
+type Ports struct {
+	Web int
+	API int
+}
+
+type Database struct {
+	Name     string
+	User     string
+	Password string
+	Host     string
+	Port     int
+}
+
+// URL is the connection string for the database.
+func (d Database) URL() string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s", d.User, d.Password, d.Host, d.Port, d.Name)
+}
+
+type Auth struct {
+	Providers []string // sign-in methods, each one of AuthProviders
+}
+
+// Has reports whether the sign-in method provider is enabled.
+func (a Auth) Has(provider string) bool {
+	for _, p := range a.Providers {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// Social returns the enabled OAuth providers, in the order given.
+func (a Auth) Social() []SocialProvider {
+	var out []SocialProvider
+	for _, p := range a.Providers {
+		if sp, ok := socialProviders[p]; ok {
+			out = append(out, sp)
+		}
+	}
+	return out
+}
+
+// SocialProvider is an OAuth sign-in method as the templates wire it up.
+type SocialProvider struct {
+	ID    string // Better Auth provider id, e.g. "github"
+	Label string // shown on the sign-in button
+	Env   string // prefix of its credential variables, e.g. GITHUB_CLIENT_ID
+}
+
+// AuthProviders are the sign-in methods the templates support.
+var AuthProviders = []string{"email", "github", "google"}
+
+var socialProviders = map[string]SocialProvider{
+	"github": {ID: "github", Label: "GitHub", Env: "GITHUB"},
+	"google": {ID: "google", Label: "Google", Env: "GOOGLE"},
+}
+
+type AI struct {
+	Enabled  bool
+	Provider string
+	Model    string
+}
+
+// Options shared by the built-in archetypes. Each archetype declares the
+// ones it honours.
+var (
+	OptModule  = Option{Name: "module", Kind: KindString, Usage: "Go module path, e.g. github.com/org/project (default: the project name)", Check: module.CheckImportPath}
+	OptAI      = Option{Name: "ai", Kind: KindBool, Default: "false", Usage: "Enable AI Features (OpenAI)"}
+	OptDBName  = Option{Name: "db-name", Kind: KindString, Usage: "Postgres database name (default: the project name in snake_case)", Check: CheckDBName}
+	OptAuth    = Option{Name: "auth", Kind: KindString, Default: "email", Usage: "Sign-in methods, comma-separated: " + strings.Join(AuthProviders, ", "), Check: CheckAuth}
+	OptWebPort = Option{Name: "web-port", Kind: KindInt, Default: "3000", Usage: "Port the web app listens on", Check: CheckPort}
+	OptAPIPort = Option{Name: "api-port", Kind: KindInt, Default: "8080", Usage: "Port the api listens on", Check: CheckPort}
+)
+
+// CheckPort accepts a TCP port number, 1-65535.
+func CheckPort(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a port number", value)
+	}
+	if n < 1 || n > 65535 {
+		return fmt.Errorf("%d is out of range 1-65535", n)
+	}
+	return nil
+}
+
+// CheckAuth accepts a comma-separated list of AuthProviders.
+func CheckAuth(value string) error {
+	providers := ParseAuth(value)
+	if len(providers) == 0 {
+		return fmt.Errorf("name at least one of %s", strings.Join(AuthProviders, ", "))
+	}
+	for _, p := range providers {
+		if !slices.Contains(AuthProviders, p) {
+			return fmt.Errorf("unknown auth provider %q (expected %s)", p, strings.Join(AuthProviders, ", "))
+		}
+	}
+	return nil
+}
+
+// ParseAuth splits a comma-separated auth option value, dropping blanks
+// and repeats.
+func ParseAuth(value string) []string {
+	var out []string
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" && !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ProjectOptions resolves p into the template data model, filling in the
+// defaults for anything its archetype does not expose.
+func (p Project) ProjectOptions() ProjectOptions {
+	names := DeriveNames(p.Name)
+	o := ProjectOptions{
+		Name:   p.Name,
+		Names:  names,
+		Module: names.Module,
+		Ports:  Ports{Web: 3000, API: 8080},
+		Database: Database{
+			Name:     names.Snake,
+			User:     "postgres",
+			Password: "password",
+			Host:     "localhost",
+			Port:     5432,
+		},
+		Auth: Auth{Providers: []string{"email"}},
+		AI:   AI{Model: "gpt-4o-mini"},
+	}
+	if path := p.Options.String(OptModule.Name); path != "" {
+		o.Module = path
+	}
+	if auth := ParseAuth(p.Options.String(OptAuth.Name)); len(auth) > 0 {
+		o.Auth.Providers = auth
+	}
+	if name := p.Options.String(OptDBName.Name); name != "" {
+		o.Database.Name = name
+	}
+	if port := p.Options.Int(OptWebPort.Name); port > 0 {
+		o.Ports.Web = port
+	}
+	if port := p.Options.Int(OptAPIPort.Name); port > 0 {
+		o.Ports.API = port
+	}
+	if p.Options.Bool(OptAI.Name) {
+		o.AI.Enabled = true
+		o.AI.Provider = "openai"
+	}
+	return o
+}
@@ -0,0 +1,100 @@
+package archetype
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Archetype{}
+)
+
+// Register makes an archetype available by name. It panics if the name is
+// empty or already taken, since that is a programming error.
+func Register(a Archetype) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	name := a.Name()
+	if name == "" {
+		panic("archetype: Register with empty name")
+	}
+	if _, dup := registry[name]; dup {
+		panic("archetype: Register called twice for " + name)
+	}
+	registry[name] = a
+}
+
+// Lookup returns the archetype registered under name.
+func Lookup(name string) (Archetype, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	a, ok := registry[name]
+	return a, ok
+}
+
+// All returns every registered archetype, sorted by name.
+func All() []Archetype {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	all := make([]Archetype, 0, len(registry))
+	for _, a := range registry {
+		all = append(all, a)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name() < all[j].Name() })
+	return all
+}
+
+// Names returns the names of every registered archetype, sorted.
+func Names() []string {
+	var names []string
+	for _, a := range All() {
+		names = append(names, a.Name())
+	}
+	return names
+}
+
+// Resolve validates raw option values against the archetype's schema and
+// fills in defaults for anything left unset.
+func Resolve(a Archetype, raw map[string]string) (Values, error) {
+	schema := map[string]Option{}
+	for _, opt := range a.Options() {
+		schema[opt.Name] = opt
+	}
+
+	for name, val := range raw {
+		opt, ok := schema[name]
+		if !ok {
+			return nil, fmt.Errorf("archetype %s has no option %q", a.Name(), name)
+		}
+		switch opt.Kind {
+		case KindBool:
+			if _, err := strconv.ParseBool(val); err != nil {
+				return nil, fmt.Errorf("option %q expects a bool, got %q", name, val)
+			}
+		case KindInt:
+			if _, err := strconv.Atoi(val); err != nil {
+				return nil, fmt.Errorf("option %q expects an integer, got %q", name, val)
+			}
+		}
+		if opt.Check != nil && val != "" {
+			if err := opt.Check(val); err != nil {
+				return nil, fmt.Errorf("option %q: %w", name, err)
+			}
+		}
+	}
+
+	values := Values{}
+	for name, opt := range schema {
+		values[name] = opt.Default
+		if val, ok := raw[name]; ok {
+			values[name] = val
+		}
+	}
+	return values, nil
+}
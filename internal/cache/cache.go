@@ -0,0 +1,513 @@
+// Package cache keeps the local dependency cache offline generation
+// installs from: the npm packages and Go modules the archetypes pin,
+// fetched ahead of time while there is a network.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/shell"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// EnvDir overrides the cache directory when set.
+const EnvDir = "GENESIS_CACHE"
+
+// Dir is the cache directory, ~/.cache/genesis by default. It returns ""
+// if no cache directory can be determined.
+func Dir() string {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cache, "genesis")
+}
+
+// The ecosystems a Pin belongs to.
+const (
+	NPM = "npm"
+	Go  = "go"
+)
+
+// Pin is one dependency a manifest pins: an npm package at a version or
+// range, or a Go module at a version.
+type Pin struct {
+	Ecosystem string
+	Name      string
+	Version   string
+}
+
+func (p Pin) String() string { return p.Name + "@" + p.Version }
+
+// Layout of the cache directory.
+const (
+	indexName = "index.json" // the pins prepare fetched
+	bunDir    = "bun"        // BUN_INSTALL_CACHE_DIR: bun's extracted packages
+	locksDir  = "locks"      // a bun lockfile per set of npm pins
+	goDir     = "go"         // a GOMODCACHE; its cache/download is a module proxy
+)
+
+// index records what prepare fetched, so a check never has to guess
+// from bun's or Go's cache internals.
+type index struct {
+	Prepared time.Time           `json:"prepared"`
+	Pins     map[string][]string `json:"pins"`     // ecosystem -> name@version
+	Packages map[string][]string `json:"packages"` // lock key -> every npm name@version it resolved to
+}
+
+func load(dir string) (index, error) {
+	var idx index
+	data, err := os.ReadFile(filepath.Join(dir, indexName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return idx, nil
+	} else if err != nil {
+		return idx, fmt.Errorf("read cache index: %w", err)
+	}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return idx, fmt.Errorf("read cache index: %w", err)
+	}
+	return idx, nil
+}
+
+func (idx index) has(p Pin) bool {
+	for _, s := range idx.Pins[p.Ecosystem] {
+		if s == p.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// Pins reads the dependencies pinned by a package.json or go.mod.
+func Pins(name string, data []byte) ([]Pin, error) {
+	var pins []Pin
+	switch path.Base(name) {
+	case "package.json":
+		var pkg struct {
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies} {
+			for n, v := range deps {
+				pins = append(pins, Pin{Ecosystem: NPM, Name: n, Version: v})
+			}
+		}
+	case "go.mod":
+		f, err := modfile.ParseLax(name, data, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range f.Require {
+			pins = append(pins, Pin{Ecosystem: Go, Name: r.Mod.Path, Version: r.Mod.Version})
+		}
+	default:
+		return nil, fmt.Errorf("%s: not a package.json or go.mod", name)
+	}
+	sort.Slice(pins, func(i, j int) bool { return pins[i].Name < pins[j].Name })
+	return pins, nil
+}
+
+// MissingError lists the pinned dependencies the cache does not hold.
+type MissingError struct {
+	Dir  string
+	Pins []Pin
+}
+
+func (e *MissingError) Error() string {
+	names := make([]string, len(e.Pins))
+	for i, p := range e.Pins {
+		names[i] = p.Ecosystem + " " + p.String()
+	}
+	return fmt.Sprintf("offline cache %s lacks %d pinned version(s): %s; run 'genesis cache prepare' while online",
+		e.Dir, len(e.Pins), strings.Join(names, ", "))
+}
+
+// Check returns a *MissingError if the cache at dir cannot install every
+// set of pins, one set per manifest. What is checked is what the install
+// will read: each Go module's zip, and for npm the set's lockfile and
+// every package it resolves to in bun's cache.
+func Check(dir string, sets ...[]Pin) error {
+	if dir == "" {
+		return fmt.Errorf("no offline cache directory: set %s", EnvDir)
+	}
+	idx, err := load(dir)
+	if err != nil {
+		return err
+	}
+	var missing []Pin
+	for _, pins := range sets {
+		var npm []Pin
+		for _, p := range pins {
+			switch {
+			case !idx.has(p):
+				missing = append(missing, p)
+			case p.Ecosystem == Go && !hasModule(dir, p):
+				missing = append(missing, p)
+			case p.Ecosystem == NPM:
+				npm = append(npm, p)
+			}
+		}
+		if len(npm) == 0 || len(npm) < countNPM(pins) {
+			continue // already reported pin by pin
+		}
+		key := lockKey(npm)
+		if _, err := lockfile(dir, key); err != nil {
+			missing = append(missing, npm...) // cached, but never as this set
+			continue
+		}
+		for _, pkg := range idx.Packages[key] {
+			name, version := splitPackage(pkg)
+			if !hasPackage(dir, name, version) {
+				missing = append(missing, Pin{Ecosystem: NPM, Name: name, Version: version})
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingError{Dir: dir, Pins: missing}
+	}
+	return nil
+}
+
+func countNPM(pins []Pin) int {
+	n := 0
+	for _, p := range pins {
+		if p.Ecosystem == NPM {
+			n++
+		}
+	}
+	return n
+}
+
+func hasModule(dir string, p Pin) bool {
+	escPath, err1 := module.EscapePath(p.Name)
+	escVersion, err2 := module.EscapeVersion(p.Version)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(proxyDir(dir), filepath.FromSlash(escPath), "@v", escVersion+".zip"))
+	return err == nil
+}
+
+// hasPackage reports whether bun's cache holds name at version. bun keeps
+// each package extracted as <name>@<version>@@<cache version>, scoped
+// packages under their scope.
+func hasPackage(dir, name, version string) bool {
+	entries, err := os.ReadDir(filepath.Join(dir, bunDir, filepath.FromSlash(path.Dir(name))))
+	if err != nil {
+		return false
+	}
+	prefix := path.Base(name) + "@" + version + "@@"
+	for _, e := range entries {
+		if e.IsDir() && strings.HasPrefix(e.Name(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitPackage splits name@version, where name may be @scope/name.
+func splitPackage(s string) (name, version string) {
+	i := strings.LastIndex(s, "@")
+	if i <= 0 {
+		return s, ""
+	}
+	return s[:i], s[i+1:]
+}
+
+// lockKey names a set of npm pins: the same dependencies resolve the same
+// whatever the project is called.
+func lockKey(pins []Pin) string {
+	names := make([]string, len(pins))
+	for i, p := range pins {
+		names[i] = p.String()
+	}
+	sort.Strings(names)
+	sum := sha256.Sum256([]byte(strings.Join(names, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// lockfileNames are the lockfiles bun writes: text since bun 1.2, binary
+// before.
+var lockfileNames = []string{"bun.lock", "bun.lockb"}
+
+// lockfile returns the path of the lockfile prepared for key.
+func lockfile(dir, key string) (string, error) {
+	for _, name := range lockfileNames {
+		p := filepath.Join(dir, locksDir, key, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no lockfile prepared for %s", key)
+}
+
+// proxyDir is the part of the Go module cache laid out as a module proxy.
+func proxyDir(dir string) string {
+	return filepath.Join(dir, goDir, "cache", "download")
+}
+
+// Offline returns the plan's hooks with those that have an offline
+// equivalent (bun install, go mod tidy) pointed at the cache at dir, and
+// the lockfiles that must sit next to the package.json files so bun
+// installs exactly what prepare resolved. It fails first if the cache
+// cannot serve a version the manifests behind them pin. Other network
+// hooks are left for the hook policy to skip.
+func Offline(dir string, plan render.Plan) ([]render.Command, []render.File, error) {
+	files := map[string][]byte{}
+	for _, f := range plan.Files {
+		files[f.Path] = f.Content
+	}
+
+	var (
+		sets  [][]Pin
+		locks []render.File
+	)
+	cmds := make([]render.Command, len(plan.Commands))
+	for i, c := range plan.Commands {
+		var manifest string
+		switch {
+		case c.Name == "bun" && len(c.Args) == 1 && c.Args[0] == "install":
+			manifest = "package.json"
+			c.Env = append(c.Env, bunEnv(dir)...)
+		case c.Name == "go" && strings.Join(c.Args, " ") == "mod tidy":
+			manifest = "go.mod"
+			c.Env = append(c.Env, goEnv(dir)...)
+		default:
+			cmds[i] = c
+			continue
+		}
+		c.Network = false
+		cmds[i] = c
+
+		name := path.Join(c.Dir, manifest)
+		data, ok := files[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("offline: %s runs without a %s", c, name)
+		}
+		pins, err := Pins(name, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("offline: %w", err)
+		}
+		sets = append(sets, pins)
+
+		if manifest == "package.json" && len(pins) > 0 {
+			if p, err := lockfile(dir, lockKey(pins)); err == nil {
+				content, err := os.ReadFile(p)
+				if err != nil {
+					return nil, nil, fmt.Errorf("offline: %w", err)
+				}
+				locks = append(locks, render.File{Path: path.Join(c.Dir, filepath.Base(p)), Content: content, Mode: 0644})
+			}
+		}
+	}
+	if err := Check(dir, sets...); err != nil {
+		return nil, nil, err
+	}
+	return cmds, locks, nil
+}
+
+// bunEnv points bun at the cache prepare filled. With the prepared
+// lockfile in place bun has nothing to resolve, and everything to link is
+// already extracted there.
+func bunEnv(dir string) []string {
+	return []string{"BUN_INSTALL_CACHE_DIR=" + filepath.Join(dir, bunDir)}
+}
+
+// goEnv makes the go command resolve modules from the cache alone: its
+// module cache doubles as a file proxy. There is no checksum database to
+// ask offline; the modules in it were verified when prepare fetched them.
+func goEnv(dir string) []string {
+	return []string{
+		"GOPROXY=file://" + filepath.ToSlash(proxyDir(dir)),
+		"GOSUMDB=off",
+	}
+}
+
+// Prepare fetches into the cache at dir everything the package.json and
+// go.mod files in plans pin, by installing each in a scratch copy of its
+// plan, and records the pins so Check can find them. It needs the network.
+func Prepare(ctx context.Context, dir string, plans []render.Plan) ([]Pin, error) {
+	report := progress.From(ctx)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	scratch, err := os.MkdirTemp("", "genesis-cache-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(scratch)
+
+	idx, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if idx.Pins == nil {
+		idx.Pins = map[string][]string{}
+	}
+	if idx.Packages == nil {
+		idx.Packages = map[string][]string{}
+	}
+
+	var pins []Pin
+	for i, plan := range plans {
+		root := filepath.Join(scratch, fmt.Sprint(i))
+		for _, f := range plan.Files {
+			name := filepath.Join(root, filepath.FromSlash(f.Path))
+			if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(name, f.Content, 0644); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, f := range plan.Files {
+			var (
+				runs [][]string
+				env  []string
+			)
+			switch path.Base(f.Path) {
+			case "package.json", "go.mod":
+			default:
+				continue
+			}
+			p, err := Pins(f.Path, f.Content)
+			if err != nil {
+				return nil, err
+			}
+			if path.Base(f.Path) == "package.json" {
+				runs = [][]string{{"bun", "install"}}
+				env = bunEnv(dir)
+			} else {
+				// Every pinned version, whether or not the project imports
+				// it yet, then whatever else tidy needs. -e: the scratch
+				// copy lacks the code sqlc generates.
+				download := []string{"go", "mod", "download"}
+				for _, pin := range p {
+					download = append(download, pin.String())
+				}
+				runs = [][]string{download, {"go", "mod", "tidy", "-e"}}
+				env = []string{"GOMODCACHE=" + filepath.Join(dir, goDir), "GOFLAGS=-modcacherw"}
+			}
+
+			report.Step("> Caching the %d dependencies pinned by %s...", len(p), f.Path)
+			work := filepath.Join(root, filepath.FromSlash(path.Dir(f.Path)))
+			for _, r := range runs {
+				if err := shell.RunEnv(ctx, work, env, r[0], r[1:]...); err != nil {
+					return nil, fmt.Errorf("cache %s: %w", f.Path, err)
+				}
+			}
+			if path.Base(f.Path) == "package.json" && len(p) > 0 {
+				key := lockKey(p)
+				if err := keepLockfile(work, filepath.Join(dir, locksDir, key)); err != nil {
+					return nil, fmt.Errorf("cache %s: %w", f.Path, err)
+				}
+				if idx.Packages[key], err = installed(filepath.Join(work, "node_modules")); err != nil {
+					return nil, fmt.Errorf("cache %s: %w", f.Path, err)
+				}
+			}
+			pins = append(pins, p...)
+		}
+	}
+
+	for _, p := range pins {
+		if !idx.has(p) {
+			idx.Pins[p.Ecosystem] = append(idx.Pins[p.Ecosystem], p.String())
+		}
+	}
+	for _, s := range idx.Pins {
+		sort.Strings(s)
+	}
+	idx.Prepared = time.Now().UTC()
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, indexName), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("write cache index: %w", err)
+	}
+	return pins, nil
+}
+
+// isPackageDir reports whether dir is a package in a node_modules
+// directory: node_modules/<name> or node_modules/@<scope>/<name>.
+func isPackageDir(dir string) bool {
+	parent := filepath.Dir(dir)
+	if strings.HasPrefix(filepath.Base(parent), "@") {
+		parent = filepath.Dir(parent)
+	}
+	return filepath.Base(parent) == "node_modules"
+}
+
+// keepLockfile copies the lockfile bun wrote in work into dst.
+func keepLockfile(work, dst string) error {
+	for _, name := range lockfileNames {
+		data, err := os.ReadFile(filepath.Join(work, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, name), data, 0644)
+	}
+	return errors.New("bun install wrote no lockfile")
+}
+
+// installed lists every package under a node_modules tree as
+// name@version, nested node_modules included.
+func installed(nodeModules string) ([]string, error) {
+	seen := map[string]bool{}
+	err := filepath.WalkDir(nodeModules, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && p != nodeModules {
+			return filepath.SkipDir // .bin, .cache
+		}
+		if d.Name() != "package.json" || !isPackageDir(filepath.Dir(p)) {
+			return nil
+		}
+		var pkg struct{ Name, Version string }
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Name != "" && pkg.Version != "" {
+			seen[pkg.Name+"@"+pkg.Version] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(seen))
+	for pkg := range seen {
+		out = append(out, pkg)
+	}
+	sort.Strings(out)
+	return out, nil
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holodanger/genesis/internal/render"
+)
+
+func TestOffline(t *testing.T) {
+	plan := render.Plan{
+		Files: []render.File{
+			{Path: "web/package.json", Content: []byte(`{"dependencies": {"next": "16.1.1"}, "devDependencies": {"typescript": "^5"}}`)},
+			{Path: "api/go.mod", Content: []byte("module example.com/api\n\ngo 1.23\n\nrequire github.com/google/uuid v1.6.0\n")},
+		},
+		Commands: []render.Command{
+			{Dir: "web", Hook: "install", Name: "bun", Args: []string{"install"}, Network: true},
+			{Dir: "api", Hook: "tidy", Name: "go", Args: []string{"mod", "tidy"}, Network: true},
+			{Dir: "api", Hook: "codegen", Name: "curl", Args: []string{"example.com"}, Network: true},
+		},
+	}
+
+	dir := t.TempDir()
+	_, _, err := Offline(dir, plan)
+	var missing *MissingError
+	if !errors.As(err, &missing) || len(missing.Pins) != 3 {
+		t.Fatalf("empty cache: got %v, want all 3 pins missing", err)
+	}
+
+	// Recorded, but the module itself is gone from the module cache and
+	// bun's cache was never filled.
+	npm := []Pin{{NPM, "next", "16.1.1"}, {NPM, "typescript", "^5"}}
+	key := lockKey(npm)
+	idx := index{
+		Pins: map[string][]string{
+			NPM: {"next@16.1.1", "typescript@^5"},
+			Go:  {"github.com/google/uuid@v1.6.0"},
+		},
+		Packages: map[string][]string{key: {"@next/env@16.1.1", "next@16.1.1", "typescript@5.9.3"}},
+	}
+	data, _ := json.Marshal(idx)
+	write(t, filepath.Join(dir, indexName), data)
+	_, _, err = Offline(dir, plan)
+	if !errors.As(err, &missing) || len(missing.Pins) != 3 || !strings.Contains(err.Error(), "go github.com/google/uuid@v1.6.0") {
+		t.Fatalf("nothing but the index: got %v, want the module and the unprepared npm set", err)
+	}
+
+	write(t, filepath.Join(proxyDir(dir), "github.com", "google", "uuid", "@v", "v1.6.0.zip"), nil)
+	write(t, filepath.Join(dir, locksDir, key, "bun.lock"), []byte("{}\n"))
+	write(t, filepath.Join(dir, bunDir, "next@16.1.1@@@1", "package.json"), nil)
+	write(t, filepath.Join(dir, bunDir, "typescript@5.9.3@@@1", "package.json"), nil)
+	_, _, err = Offline(dir, plan)
+	if !errors.As(err, &missing) || len(missing.Pins) != 1 || !strings.Contains(err.Error(), "npm @next/env@16.1.1") {
+		t.Fatalf("package pruned from bun's cache: got %v", err)
+	}
+
+	write(t, filepath.Join(dir, bunDir, "@next", "env@16.1.1@@@1", "package.json"), nil)
+	cmds, locks, err := Offline(dir, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := cmds[0]; c.Name != "bun" || c.Network || len(c.Env) == 0 || !strings.HasPrefix(c.Env[0], "BUN_INSTALL_CACHE_DIR=") {
+		t.Errorf("install = %s %v (network %v), want bun install from the cache", c, c.Env, c.Network)
+	}
+	if c := cmds[1]; c.Network || len(c.Env) == 0 || !strings.HasPrefix(c.Env[0], "GOPROXY=file://") {
+		t.Errorf("tidy = %s %v (network %v), want a file proxy", c, c.Env, c.Network)
+	}
+	if c := cmds[2]; !c.Network {
+		t.Errorf("%s lost Network; it has no offline equivalent", c)
+	}
+	if len(locks) != 1 || locks[0].Path != "web/bun.lock" {
+		t.Errorf("lockfiles = %v, want web/bun.lock", locks)
+	}
+}
+
+func write(t *testing.T, name string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
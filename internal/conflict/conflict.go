@@ -0,0 +1,139 @@
+package conflict
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/textdiff"
+)
+
+// Policy decides what happens to existing files a generation would replace.
+type Policy int
+
+const (
+	// Refuse aborts when the target directory is not empty.
+	Refuse Policy = iota
+	// Force overwrites every conflicting file.
+	Force
+	// SkipExisting keeps every existing file and only adds new ones.
+	SkipExisting
+	// Ask prompts for each conflicting file.
+	Ask
+)
+
+// Conflict is a planned file that already exists on disk with different
+// content.
+type Conflict struct {
+	Path     string // slash-separated, relative to the project root
+	Existing []byte
+	Planned  []byte
+}
+
+// ErrNotEmpty is returned under Refuse when the target has content.
+var ErrNotEmpty = errors.New("target directory is not empty")
+
+// ErrAborted is returned when the user quits an interactive resolution.
+var ErrAborted = errors.New("aborted by user")
+
+// Detect compares the planned files against what is already under root.
+// Files with identical content are not conflicts. It also reports whether
+// root exists and has any entries at all.
+func Detect(root string, files []render.File) (conflicts []Conflict, nonEmpty bool, err error) {
+	entries, err := os.ReadDir(root)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	}
+
+	for _, f := range files {
+		existing, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		if !bytes.Equal(existing, f.Content) {
+			conflicts = append(conflicts, Conflict{Path: f.Path, Existing: existing, Planned: f.Content})
+		}
+	}
+	return conflicts, len(entries) > 0, nil
+}
+
+// Resolve applies policy to the detected conflicts and returns the paths
+// to keep as they are. Under Ask, prompts are read from in and written to
+// out.
+func Resolve(policy Policy, conflicts []Conflict, nonEmpty bool, in io.Reader, out io.Writer) (keep []string, err error) {
+	switch policy {
+	case Force:
+		return nil, nil
+	case SkipExisting:
+		for _, c := range conflicts {
+			keep = append(keep, c.Path)
+		}
+		return keep, nil
+	case Ask:
+		return ask(conflicts, in, out)
+	}
+
+	if !nonEmpty {
+		return nil, nil
+	}
+	if len(conflicts) == 0 {
+		return nil, fmt.Errorf("%w; use -force or -skip-existing to spawn into it", ErrNotEmpty)
+	}
+	var paths []string
+	for _, c := range conflicts {
+		paths = append(paths, c.Path)
+	}
+	return nil, fmt.Errorf("%w: %d file(s) would be overwritten (%s); use -force or -skip-existing",
+		ErrNotEmpty, len(conflicts), strings.Join(paths, ", "))
+}
+
+func ask(conflicts []Conflict, in io.Reader, out io.Writer) ([]string, error) {
+	var keep []string
+	r := bufio.NewReader(in)
+	all := ""
+
+	for _, c := range conflicts {
+		answer := all
+		for answer == "" {
+			fmt.Fprintf(out, "⚠️  [CONFLICT] %s already exists and differs.\n", c.Path)
+			fmt.Fprint(out, "    [o]verwrite, [s]kip, [d]iff, overwrite [a]ll, s[k]ip all, [q]uit? ")
+
+			line, err := r.ReadString('\n')
+			if err != nil && line == "" {
+				return nil, fmt.Errorf("no answer for %s (input closed); use -force or -skip-existing", c.Path)
+			}
+
+			switch strings.TrimSpace(strings.ToLower(line)) {
+			case "o", "overwrite":
+				answer = "o"
+			case "s", "skip":
+				answer = "s"
+			case "a", "all":
+				answer, all = "o", "o"
+			case "k":
+				answer, all = "s", "s"
+			case "d", "diff":
+				fmt.Fprint(out, textdiff.Unified(c.Path+" (existing)", c.Path+" (genesis)", c.Existing, c.Planned))
+			case "q", "quit":
+				return nil, ErrAborted
+			}
+		}
+
+		if answer == "s" {
+			keep = append(keep, c.Path)
+		}
+	}
+	return keep, nil
+}
@@ -0,0 +1,100 @@
+package conflict
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holodanger/genesis/internal/render"
+)
+
+func TestDetect(t *testing.T) {
+	files := []render.File{
+		{Path: "same.txt", Content: []byte("same\n")},
+		{Path: "dir/differs.txt", Content: []byte("genesis\n")},
+		{Path: "new.txt", Content: []byte("new\n")},
+	}
+
+	conflicts, nonEmpty, err := Detect(filepath.Join(t.TempDir(), "missing"), files)
+	if err != nil || nonEmpty || len(conflicts) != 0 {
+		t.Fatalf("missing root: got %v, %v, %v", conflicts, nonEmpty, err)
+	}
+
+	root := t.TempDir()
+	conflicts, nonEmpty, err = Detect(root, files)
+	if err != nil || nonEmpty || len(conflicts) != 0 {
+		t.Fatalf("empty root: got %v, %v, %v", conflicts, nonEmpty, err)
+	}
+
+	for name, content := range map[string]string{"same.txt": "same\n", "dir/differs.txt": "mine\n"} {
+		name = filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conflicts, nonEmpty, err = Detect(root, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !nonEmpty || len(conflicts) != 1 || conflicts[0].Path != "dir/differs.txt" ||
+		string(conflicts[0].Existing) != "mine\n" || string(conflicts[0].Planned) != "genesis\n" {
+		t.Errorf("got %+v, nonEmpty %v; want only dir/differs.txt", conflicts, nonEmpty)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	conflicts := []Conflict{
+		{Path: "a", Existing: []byte("mine\n"), Planned: []byte("genesis\n")},
+		{Path: "b", Existing: []byte("mine\n"), Planned: []byte("genesis\n")},
+		{Path: "c", Existing: []byte("mine\n"), Planned: []byte("genesis\n")},
+	}
+
+	cases := []struct {
+		name      string
+		policy    Policy
+		conflicts []Conflict
+		nonEmpty  bool
+		input     string
+		keep      string
+		err       error  // errors.Is target; nil means success
+		errText   string // or a substring of the error
+		output    string // a substring of what was printed
+	}{
+		{name: "force", policy: Force, conflicts: conflicts, nonEmpty: true},
+		{name: "skip existing", policy: SkipExisting, conflicts: conflicts, nonEmpty: true, keep: "a,b,c"},
+		{name: "refuse, empty", policy: Refuse},
+		{name: "refuse, stray files", policy: Refuse, nonEmpty: true, err: ErrNotEmpty},
+		{name: "refuse, conflicts", policy: Refuse, conflicts: conflicts, nonEmpty: true, err: ErrNotEmpty, errText: "3 file(s) would be overwritten (a, b, c)"},
+		{name: "ask, one by one", policy: Ask, conflicts: conflicts, input: "o\ns\noverwrite\n", keep: "b"},
+		{name: "ask, overwrite all", policy: Ask, conflicts: conflicts, input: "s\na\n", keep: "a"},
+		{name: "ask, skip all", policy: Ask, conflicts: conflicts, input: "o\nk\n", keep: "b,c"},
+		{name: "ask, diff then answer", policy: Ask, conflicts: conflicts[:1], input: "d\ns\n", keep: "a", output: "+genesis"},
+		{name: "ask, unknown answer asks again", policy: Ask, conflicts: conflicts[:1], input: "maybe\no\n"},
+		{name: "ask, quit", policy: Ask, conflicts: conflicts, input: "o\nq\n", err: ErrAborted},
+		{name: "ask, input closed", policy: Ask, conflicts: conflicts, input: "o\n", errText: "no answer for b"},
+	}
+
+	for _, tc := range cases {
+		var out strings.Builder
+		keep, err := Resolve(tc.policy, tc.conflicts, tc.nonEmpty, strings.NewReader(tc.input), &out)
+		switch {
+		case tc.err == nil && tc.errText == "" && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.err != nil && !errors.Is(err, tc.err):
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.err)
+		case tc.errText != "" && (err == nil || !strings.Contains(err.Error(), tc.errText)):
+			t.Errorf("%s: got %v, want an error containing %q", tc.name, err, tc.errText)
+		}
+		if got := strings.Join(keep, ","); got != tc.keep {
+			t.Errorf("%s: kept %q, want %q", tc.name, got, tc.keep)
+		}
+		if !strings.Contains(out.String(), tc.output) {
+			t.Errorf("%s: printed %q, want %q in it", tc.name, out.String(), tc.output)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package drift
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/render"
+)
+
+// Status is how a project file relates to the template baseline.
+type Status string
+
+const (
+	Untouched Status = "untouched" // identical to what the templates produce
+	Modified  Status = "modified"  // produced by the templates, but edited
+	Deleted   Status = "deleted"   // produced by the templates, but missing
+	Added     Status = "added"     // present, but not produced by the templates
+)
+
+// Entry is one file in a drift report. Baseline and Current hold the
+// template and on-disk content, nil where the file is absent.
+type Entry struct {
+	Path     string
+	Status   Status
+	Baseline []byte
+	Current  []byte
+}
+
+// skipDirs are dependency caches and tool state that never count as
+// drift, wherever they are.
+var skipDirs = map[string]bool{
+	".git":         true,
+	".next":        true,
+	"node_modules": true,
+}
+
+// outputDirs are build outputs, skipped only at the top of a node: deeper
+// down a directory called build is as likely to be source.
+var outputDirs = map[string]bool{
+	"bin":   true,
+	"dist":  true,
+	"out":   true,
+	"build": true,
+}
+
+// nodes returns the directory of every node in baseline: the project root
+// and, in a hybrid project, each directory holding its own package.json
+// or go.mod.
+func nodes(baseline render.Plan) map[string]bool {
+	dirs := map[string]bool{".": true}
+	for _, f := range baseline.Files {
+		switch path.Base(f.Path) {
+		case "package.json", "go.mod":
+			dirs[path.Dir(f.Path)] = true
+		}
+	}
+	return dirs
+}
+
+// Compare reports how the project at root has drifted from baseline. When
+// prefixes are given, only paths under one of them are reported.
+func Compare(root string, baseline render.Plan, prefixes []string) ([]Entry, error) {
+	want := map[string][]byte{}
+	for _, f := range baseline.Files {
+		if !manifest.IsProvenance(f.Path) {
+			want[f.Path] = f.Content
+		}
+	}
+
+	top := nodes(baseline)
+
+	var entries []Entry
+	seen := map[string]bool{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel != "." && (skipDirs[d.Name()] || outputDirs[d.Name()] && top[path.Dir(rel)] ||
+				rel == filepath.ToSlash(filepath.Dir(manifest.BaselineName))) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if manifest.IsProvenance(rel) || !d.Type().IsRegular() || !under(rel, prefixes) {
+			return nil
+		}
+
+		current, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		seen[rel] = true
+
+		baselineContent, generated := want[rel]
+		switch {
+		case !generated:
+			entries = append(entries, Entry{Path: rel, Status: Added, Current: current})
+		case bytes.Equal(current, baselineContent):
+			entries = append(entries, Entry{Path: rel, Status: Untouched, Baseline: baselineContent, Current: current})
+		default:
+			entries = append(entries, Entry{Path: rel, Status: Modified, Baseline: baselineContent, Current: current})
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	for p, content := range want {
+		if !seen[p] && under(p, prefixes) {
+			entries = append(entries, Entry{Path: p, Status: Deleted, Baseline: content})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// under reports whether p lies under one of prefixes (all paths if none).
+func under(p string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(prefix)), "/")
+		if prefix == "." || p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
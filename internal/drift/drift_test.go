@@ -0,0 +1,90 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/render"
+)
+
+func TestCompare(t *testing.T) {
+	baseline := render.Plan{Files: []render.File{
+		{Path: "README.md", Content: []byte("# x\n")},
+		{Path: "api/go.mod", Content: []byte("module x\n")},
+		{Path: "api/main.go", Content: []byte("package main\n")},
+		{Path: "api/internal/build/build.go", Content: []byte("package build\n")},
+		{Path: "web/package.json", Content: []byte("{}\n")},
+		{Path: manifest.FileName, Content: []byte("{}\n")},
+	}}
+
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"README.md":                   "# x\n",
+		"api/go.mod":                  "module x\n",
+		"api/main.go":                 "package main // edited\n",
+		"api/internal/build/build.go": "package build\n",
+		"api/internal/build/extra.go": "package build\n",
+		"notes.txt":                   "mine\n",
+		manifest.FileName:             "{\"changed\": true}\n",
+		manifest.BaselineName:         "snapshot",
+		// Build outputs and caches, at the top of a node or anywhere.
+		"bin/x":                       "binary",
+		"api/bin/api":                 "binary",
+		"web/out/index.html":          "<html>",
+		"web/node_modules/a/a.js":     "a",
+		"api/internal/node_modules/x": "x",
+	} {
+		name = filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		prefixes []string
+		want     map[string]Status
+	}{
+		{"whole project", nil, map[string]Status{
+			"README.md":                   Untouched,
+			"api/go.mod":                  Untouched,
+			"api/internal/build/build.go": Untouched,
+			"api/internal/build/extra.go": Added,
+			"api/main.go":                 Modified,
+			"notes.txt":                   Added,
+			"web/package.json":            Deleted,
+		}},
+		{"one node", []string{"web/"}, map[string]Status{
+			"web/package.json": Deleted,
+		}},
+		{"nested prefix", []string{"api/internal"}, map[string]Status{
+			"api/internal/build/build.go": Untouched,
+			"api/internal/build/extra.go": Added,
+		}},
+	}
+
+	for _, tc := range cases {
+		entries, err := Compare(root, baseline, tc.prefixes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]Status{}
+		for _, e := range entries {
+			got[e.Path] = e.Status
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for p, status := range tc.want {
+			if got[p] != status {
+				t.Errorf("%s: %s is %q, want %q", tc.name, p, got[p], status)
+			}
+		}
+	}
+}
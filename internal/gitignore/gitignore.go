@@ -0,0 +1,98 @@
+// Package gitignore merges the .gitignore files of a project's nodes into
+// one for the project root.
+package gitignore
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Node is the .gitignore of one sub-project, at Dir under the root.
+type Node struct {
+	Dir     string
+	Content []byte
+}
+
+// Merge returns a root .gitignore that ignores exactly what the nodes'
+// files ignore. Patterns every node shares are listed once, as they are;
+// the rest are rewritten to apply only inside their node: "main" in api
+// becomes "api/**/main" and "/dist" becomes "api/dist".
+func Merge(header string, nodes []Node) []byte {
+	patterns := make([][]string, len(nodes))
+	count := map[string]int{}
+	for i, n := range nodes {
+		seen := map[string]bool{}
+		for _, line := range strings.Split(string(n.Content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || seen[line] {
+				continue
+			}
+			seen[line] = true
+			patterns[i] = append(patterns[i], line)
+			count[line]++
+		}
+	}
+
+	var b bytes.Buffer
+	if header != "" {
+		for _, line := range strings.Split(strings.TrimSpace(header), "\n") {
+			fmt.Fprintf(&b, "# %s\n", line)
+		}
+		b.WriteString("\n")
+	}
+
+	// Negations depend on the order of the patterns around them, so only
+	// plain patterns are shared.
+	var shared []string
+	listed := map[string]bool{}
+	for _, p := range patterns {
+		for _, line := range p {
+			if count[line] == len(nodes) && !strings.HasPrefix(line, "!") && !listed[line] {
+				listed[line] = true
+				shared = append(shared, line)
+			}
+		}
+	}
+	if len(shared) > 0 {
+		b.WriteString("# Every node\n")
+		for _, line := range shared {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	for i, n := range nodes {
+		var own []string
+		for _, line := range patterns[i] {
+			if !listed[line] {
+				own = append(own, scope(n.Dir, line))
+			}
+		}
+		if len(own) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# %s/\n", n.Dir)
+		for _, line := range own {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// scope rewrites a pattern from the .gitignore in dir so it means the same
+// thing in the root .gitignore.
+func scope(dir, pattern string) string {
+	neg := ""
+	if strings.HasPrefix(pattern, "!") {
+		neg, pattern = "!", pattern[1:]
+	}
+	// A slash anywhere but at the end anchors the pattern to its
+	// .gitignore's directory; otherwise it matches at any depth.
+	if strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		return neg + dir + "/" + strings.TrimPrefix(pattern, "/")
+	}
+	return neg + dir + "/**/" + pattern
+}
@@ -0,0 +1,28 @@
+package gitignore
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	got := string(Merge("Generated by Genesis", []Node{
+		{Dir: "web", Content: []byte("# Deps\nnode_modules/\n.env\n/out\n\n.DS_Store\n")},
+		{Dir: "api", Content: []byte("bin/\nmain\n.DS_Store\n.env\n!keep/main\n")},
+	}))
+	want := `# Generated by Genesis
+
+# Every node
+.env
+.DS_Store
+
+# web/
+web/**/node_modules/
+web/out
+
+# api/
+api/**/bin/
+api/**/main
+!api/keep/main
+`
+	if got != want {
+		t.Errorf("Merge =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package goservice
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/render"
+)
+
+func init() {
+	archetype.Register(Archetype{})
+}
+
+// Archetype registers the standalone Go API node ("Backend Spear").
+type Archetype struct{}
+
+func (Archetype) Name() string { return "go" }
+
+func (Archetype) Description() string {
+	return "Backend Spear: Go REST API + Postgres + sqlc"
+}
+
+func (Archetype) Options() []archetype.Option {
+	return []archetype.Option{
+		archetype.OptAI,
+		archetype.OptModule,
+		archetype.OptDBName,
+		archetype.OptAPIPort,
+	}
+}
+
+func (Archetype) Launch() string { return "make run" }
+
+func (Archetype) Plan(p archetype.Project) (render.Plan, error) {
+	return NewBuilder(p.Name, p.Root, p.ProjectOptions()).Render()
+}
+
+func (Archetype) Generate(ctx context.Context, p archetype.Project, w *render.Writer) error {
+	return NewBuilder(p.Name, p.Root, p.ProjectOptions()).Build(ctx, w)
+}
+
+func (Archetype) Templates() fs.FS { return Templates() }
+
+func (Archetype) TemplateSet() ([]string, any) {
+	o := archetype.Project{Name: "lint"}.ProjectOptions()
+	o.AI.Enabled = true
+	b := NewBuilder("lint", "", o)
+	return b.files(), b.Options
+}
@@ -1,98 +1,90 @@
 package goservice
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"text/template"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/hook"
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/render"
 )
 
+// Builder renders a Go service. Name is the service's logical name, used
+// in messages; Dir is where its files go. Neither depends on the working
+// directory, so a service can be built as a node of a larger project.
 type Builder struct {
-	Name   string
-	WithAI bool
+	Name    string
+	Dir     string
+	Options archetype.ProjectOptions
 }
 
-func NewBuilder(name string, withAI bool) *Builder {
+func NewBuilder(name, dir string, o archetype.ProjectOptions) *Builder {
 	return &Builder{
-		Name:   name,
-		WithAI: withAI,
+		Name:    name,
+		Dir:     dir,
+		Options: o,
 	}
 }
 
-func (b *Builder) Build() error {
-	// 1. Define the File Map
-	// Mapped to the constants in templates.go
-	files := map[string]string{
-		"go.mod":                        GoMod,
-		"cmd/api/main.go":               MainGo,
-		"internal/config/config.go":     ConfigGo,
-		"internal/server/server.go":     ServerGo,
-		"internal/server/routes.go":     RoutesGo,
-		"internal/server/middleware.go": MiddlewareGo,
-		"internal/server/audit.go":      AuditGo,
-		"internal/db/schema.sql":        SchemaSQL,
-		"internal/db/query.sql":         QuerySQL,
-		"sqlc.yaml":                     SQLCConfig,
-		".golangci.yml":                 LintConfig,
-		"compose.yml":                   DockerCompose,
-		"Makefile":                      Makefile,
-		".env":                          EnvFile,
-		".gitignore":                    GitIgnore,
+// files lists the output paths to render from the template tree.
+func (b *Builder) files() []string {
+	files := []string{
+		"go.mod",
+		"cmd/api/main.go",
+		"internal/config/config.go",
+		"internal/server/server.go",
+		"internal/server/routes.go",
+		"internal/server/middleware.go",
+		"internal/server/audit.go",
+		"internal/db/schema.sql",
+		"internal/db/query.sql",
+		"sqlc.yaml",
+		".golangci.yml",
+		"compose.yml",
+		"Makefile",
+		".env",
+		".gitignore",
 	}
 
 	// Logic Gate: Inject AI Modules
-	if b.WithAI {
-		files["internal/ai/service.go"] = AIServiceGo
-		files["internal/ai/handler.go"] = AIHandlerGo
-	}
-
-	// 2. Data for Templates
-	data := map[string]interface{}{
-		"Name":   b.Name,
-		"WithAI": b.WithAI,
+	if b.Options.AI.Enabled {
+		files = append(files, "internal/ai/service.go", "internal/ai/handler.go")
 	}
+	return files
+}
 
-	// 3. Execution Loop
-	for path, content := range files {
-		fullPath := filepath.Join(b.Name, path)
-
-		// Ensure directory exists
-		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-			return fmt.Errorf("mkdir failed: %w", err)
-		}
-
-		// Create File
-		f, err := os.Create(fullPath)
-		if err != nil {
-			return fmt.Errorf("create file failed: %w", err)
-		}
-
-		// Parse & Execute
-		tmpl, err := template.New(path).Parse(content)
-		if err != nil {
-			f.Close()
-			return fmt.Errorf("parse template failed: %w", err)
-		}
+func (b *Builder) Build(ctx context.Context, w *render.Writer) error {
+	report := progress.From(ctx)
+	report.Step("[GO] Forging Go Service %s...", b.Name)
 
-		if err := tmpl.Execute(f, data); err != nil {
-			f.Close()
-			return fmt.Errorf("execute template failed: %w", err)
-		}
-		f.Close()
+	// 1. Render the File Map
+	plan, err := b.Render()
+	if err != nil {
+		return err
 	}
 
-	// 4. Formatting & Initialization
-	fmt.Println("⚡ [SYSTEM] Initializing Go Module...")
-
-	// Run go mod tidy
-	cmd := exec.Command("go", "mod", "tidy")
-	cmd.Dir = b.Name
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("⚠️  Warning: 'go mod tidy' failed: %v\n", err)
+	// 2. Execution Loop
+	if err := w.WriteFiles(ctx, b.Dir, plan.Files); err != nil {
+		return fmt.Errorf("write file failed: %w", err)
 	}
-
 	return nil
 }
+
+// Render produces the files Build would write, in memory and relative to
+// the service root, along with the hooks that follow: generating the sqlc
+// queries, tidying the module and formatting the sources.
+func (b *Builder) Render() (render.Plan, error) {
+	rendered, err := render.Tree(Templates(), b.files(), b.Options)
+	if err != nil {
+		return render.Plan{}, err
+	}
+	return render.Plan{
+		Files: rendered,
+		Commands: []render.Command{
+			{Hook: hook.Codegen, Name: "sqlc", Args: []string{"generate"}},
+			{Hook: hook.Tidy, Name: "go", Args: []string{"mod", "tidy"}, Network: true},
+			{Hook: hook.Format, Name: "gofmt", Args: []string{"-w", "."}},
+		},
+	}, nil
+}
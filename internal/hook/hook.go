@@ -0,0 +1,254 @@
+// Package hook runs the post-generation pipeline: the commands a project
+// declares in its plan (installs, tidying, formatting, code generation),
+// in order, each under a timeout, and reports what became of each one.
+package hook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/shell"
+)
+
+// The pipeline stages a command can belong to.
+const (
+	Install = "install" // fetch dependencies
+	Tidy    = "tidy"    // reconcile the dependency manifest
+	Format  = "format"  // format generated sources
+	Git     = "git"     // initialize version control
+	Codegen = "codegen" // generate code from schemas
+)
+
+// Kinds lists the stages in the order they are documented.
+var Kinds = []string{Install, Tidy, Format, Git, Codegen}
+
+// timeouts is how long a stage may run when its command sets no Timeout.
+var timeouts = map[string]time.Duration{
+	Install: 5 * time.Minute,
+	Tidy:    3 * time.Minute,
+	Format:  time.Minute,
+	Git:     30 * time.Second,
+	Codegen: 2 * time.Minute,
+}
+
+// Timeout is how long c may run.
+func Timeout(c render.Command) time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	if d, ok := timeouts[c.Hook]; ok {
+		return d
+	}
+	return 2 * time.Minute
+}
+
+// Policy says which hooks to leave out.
+type Policy struct {
+	SkipInstall bool // skip install and tidy hooks
+	Offline     bool // skip hooks that need the network
+	InWorkTree  bool // the project is inside a git work tree already: skip git hooks
+	Jobs        int  // directories whose hooks may run at once; below 1 means 1
+}
+
+// skip returns why p leaves c out, or "".
+func (p Policy) skip(c render.Command) string {
+	switch {
+	case p.SkipInstall && (c.Hook == Install || c.Hook == Tidy):
+		return "-skip-install"
+	case p.Offline && c.Network:
+		return "-offline"
+	case p.InWorkTree && c.Hook == Git:
+		return "already inside a git work tree"
+	}
+	return ""
+}
+
+// GitInit returns the hooks that make the project root a repository whose
+// first commit, with message, holds the generated project. They belong
+// last in a pipeline so the commit includes what earlier hooks produced.
+func GitInit(message string) []render.Command {
+	return []render.Command{
+		{Hook: Git, Name: "git", Args: []string{"init", "--quiet"}},
+		{Hook: Git, Name: "git", Args: []string{"add", "--all"}},
+		{Hook: Git, Name: "git", Args: []string{"commit", "--quiet", "--message", message}},
+	}
+}
+
+// InWorkTree reports whether dir, or the nearest existing directory above
+// it, is inside a git work tree.
+func InWorkTree(ctx context.Context, dir string) bool {
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--is-inside-work-tree").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// Status is the outcome of one hook.
+type Status int
+
+const (
+	Passed Status = iota
+	Failed
+	Skipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case Passed:
+		return "passed"
+	case Failed:
+		return "failed"
+	}
+	return "skipped"
+}
+
+// Result is what became of one hook.
+type Result struct {
+	Command render.Command
+	Status  Status
+	Reason  string // why it was skipped or how it failed
+	Elapsed time.Duration
+}
+
+// Run runs cmds inside root and returns a Result for each, in the order of
+// cmds. Hooks in one directory run in order. Hooks in separate directories
+// (web/ and api/) are independent and run side by side, up to p.Jobs at a
+// time; a hook in a directory that contains others (git at the root)
+// waits for everything before it.
+//
+// A failing hook does not stop the ones after it, except later hooks of
+// the same stage in the same directory, which build on it. Cancelling ctx
+// stops the running hooks and skips the rest.
+func Run(ctx context.Context, root string, cmds []render.Command, p Policy) []Result {
+	results := make([]Result, len(cmds))
+	jobs := max(p.Jobs, 1)
+	for _, phase := range phases(cmds) {
+		sem := make(chan struct{}, jobs)
+		var wg sync.WaitGroup
+		for _, lane := range phase {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				failed := map[string]string{}
+				for _, i := range lane {
+					c := cmds[i]
+					if prev, ok := failed[c.Hook]; ok {
+						results[i] = Result{Command: c, Status: Skipped, Reason: "after failed " + prev}
+						continue
+					}
+					results[i] = run(ctx, root, c, p)
+					if results[i].Status == Failed {
+						failed[c.Hook] = c.String()
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	return results
+}
+
+// phases splits cmds, by index, into consecutive phases that can each run
+// concurrently: within a phase no directory contains another, and each
+// lane holds the hooks of one directory in order.
+func phases(cmds []render.Command) [][][]int {
+	var out [][][]int
+	var lanes [][]int
+	var dirs []string
+	for i, c := range cmds {
+		lane := -1
+		for j, d := range dirs {
+			if d == c.Dir {
+				lane = j
+			} else if nested(d, c.Dir) {
+				// Start over: this hook must see everything before it.
+				out = append(out, lanes)
+				lanes, dirs, lane = nil, nil, -1
+				break
+			}
+		}
+		if lane < 0 {
+			lanes = append(lanes, nil)
+			dirs = append(dirs, c.Dir)
+			lane = len(lanes) - 1
+		}
+		lanes[lane] = append(lanes[lane], i)
+	}
+	if len(lanes) > 0 {
+		out = append(out, lanes)
+	}
+	return out
+}
+
+// nested reports whether one of the slash directories a and b contains the
+// other ("" is the root and contains everything).
+func nested(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	return strings.HasPrefix(a+"/", b+"/") || strings.HasPrefix(b+"/", a+"/")
+}
+
+func run(ctx context.Context, root string, c render.Command, p Policy) Result {
+	r := Result{Command: c, Status: Skipped}
+	if reason := p.skip(c); reason != "" {
+		r.Reason = reason
+		return r
+	}
+	if _, err := exec.LookPath(c.Name); err != nil {
+		r.Reason = c.Name + " not found on PATH"
+		return r
+	}
+	if err := ctx.Err(); err != nil {
+		r.Reason = err.Error()
+		return r
+	}
+
+	timeout := Timeout(c)
+	hctx, cancel := context.WithTimeout(progress.WithNode(ctx, c.Dir), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := shell.RunEnv(hctx, filepath.Join(root, filepath.FromSlash(c.Dir)), c.Env, c.Name, c.Args...)
+	r.Elapsed = time.Since(start)
+	switch {
+	case err == nil:
+		r.Status = Passed
+	case errors.Is(hctx.Err(), context.DeadlineExceeded):
+		r.Status, r.Reason = Failed, fmt.Sprintf("timed out after %s", timeout)
+	default:
+		r.Status, r.Reason = Failed, err.Error()
+	}
+	return r
+}
+
+// Err joins the failures in results, or returns nil.
+func Err(results []Result) error {
+	var errs []error
+	for _, r := range results {
+		if r.Status == Failed {
+			errs = append(errs, fmt.Errorf("%s: %s", r.Command, r.Reason))
+		}
+	}
+	return errors.Join(errs...)
+}
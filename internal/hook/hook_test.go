@@ -0,0 +1,96 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holodanger/genesis/internal/render"
+)
+
+func TestRun(t *testing.T) {
+	cmds := []render.Command{
+		{Hook: Install, Name: "true", Network: true},
+		{Hook: Codegen, Name: "genesis-no-such-tool"},
+		{Hook: Format, Name: "true"},
+		{Hook: Tidy, Name: "false"},
+		{Hook: Codegen, Name: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond},
+	}
+
+	cases := []struct {
+		policy Policy
+		want   []Status
+	}{
+		{Policy{}, []Status{Passed, Skipped, Passed, Failed, Failed}},
+		{Policy{SkipInstall: true}, []Status{Skipped, Skipped, Passed, Skipped, Failed}},
+		{Policy{Offline: true}, []Status{Skipped, Skipped, Passed, Failed, Failed}},
+	}
+	for _, tc := range cases {
+		results := Run(context.Background(), t.TempDir(), cmds, tc.policy)
+		for i, r := range results {
+			if r.Status != tc.want[i] {
+				t.Errorf("%+v: %s = %s (%s), want %s", tc.policy, r.Command, r.Status, r.Reason, tc.want[i])
+			}
+		}
+		if last := results[len(results)-1]; !strings.Contains(last.Reason, "timed out") {
+			t.Errorf("%+v: sleep reason = %q, want a timeout", tc.policy, last.Reason)
+		}
+		if err := Err(results); err == nil {
+			t.Errorf("%+v: Err() = nil with failures", tc.policy)
+		}
+	}
+}
+
+func TestRunStopsStageAfterFailure(t *testing.T) {
+	results := Run(context.Background(), t.TempDir(), []render.Command{
+		{Hook: Git, Name: "false"},
+		{Hook: Git, Name: "true"},
+		{Hook: Format, Name: "true"},
+	}, Policy{})
+	if results[1].Status != Skipped || !strings.Contains(results[1].Reason, "after failed false") {
+		t.Errorf("second git hook = %s (%s), want skipped after the failure", results[1].Status, results[1].Reason)
+	}
+	if results[2].Status != Passed {
+		t.Errorf("format hook = %s, want passed", results[2].Status)
+	}
+
+	results = Run(context.Background(), t.TempDir(), GitInit("chore: init"), Policy{InWorkTree: true})
+	for _, r := range results {
+		if r.Status != Skipped {
+			t.Errorf("%s = %s inside a work tree, want skipped", r.Command, r.Status)
+		}
+	}
+}
+
+func TestRunNodesInParallel(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"web", "api"} {
+		if err := os.Mkdir(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmds := []render.Command{
+		{Dir: "web", Hook: Install, Name: "sleep", Args: []string{"0.3"}},
+		{Dir: "api", Hook: Tidy, Name: "sleep", Args: []string{"0.3"}},
+		{Dir: "api", Hook: Format, Name: "true"},
+		{Dir: "", Hook: Git, Name: "true"},
+	}
+	if got := fmt.Sprint(phases(cmds)); got != "[[[0] [1 2]] [[3]]]" {
+		t.Errorf("phases = %s", got)
+	}
+
+	start := time.Now()
+	results := Run(context.Background(), root, cmds, Policy{Jobs: 2})
+	if elapsed := time.Since(start); elapsed > 550*time.Millisecond {
+		t.Errorf("web and api hooks took %s; they did not overlap", elapsed)
+	}
+	for _, r := range results {
+		if r.Status != Passed {
+			t.Errorf("%s = %s (%s)", r.Command, r.Status, r.Reason)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package hybrid
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/render"
+)
+
+func init() {
+	archetype.Register(Archetype{})
+}
+
+// Archetype registers the twin web + api architecture.
+type Archetype struct{}
+
+func (Archetype) Name() string { return "hybrid" }
+
+func (Archetype) Description() string {
+	return "Twin Architecture: t3 web + go api sharing one database"
+}
+
+func (Archetype) Options() []archetype.Option {
+	return []archetype.Option{
+		{Name: "ai", Kind: archetype.KindBool, Default: "false", Usage: "Enable AI Features (OpenAI) in the api node"},
+		{Name: "module", Kind: archetype.KindString, Usage: "Go module path of the project; the api node is <module>/api", Check: archetype.OptModule.Check},
+		archetype.OptDBName,
+		archetype.OptAuth,
+		archetype.OptWebPort,
+		archetype.OptAPIPort,
+	}
+}
+
+func (Archetype) Launch() string { return "make dev" }
+
+func (Archetype) Plan(p archetype.Project) (render.Plan, error) {
+	return Render(p.ProjectOptions())
+}
+
+func (Archetype) Generate(ctx context.Context, p archetype.Project, w *render.Writer) error {
+	return Spawn(ctx, w, p.Root, p.ProjectOptions())
+}
+
+// Templates is the hybrid root tree only; the web and api nodes are linted
+// as the t3 and go archetypes.
+func (Archetype) Templates() fs.FS { return Templates() }
+
+func (Archetype) TemplateSet() ([]string, any) {
+	return rootFiles, archetype.Project{Name: "lint"}.ProjectOptions()
+}
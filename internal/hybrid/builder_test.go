@@ -0,0 +1,51 @@
+package hybrid
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/vfs"
+)
+
+func TestSpawnLeavesWorkingDirectory(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(wd, "twin")
+	o := archetype.Project{Name: "twin"}.ProjectOptions()
+
+	mem := vfs.NewMem()
+	if err := Spawn(context.Background(), render.NewWriter(root, mem, nil), root, o); err != nil {
+		t.Fatal(err)
+	}
+
+	if after, _ := os.Getwd(); after != wd {
+		t.Errorf("working directory changed to %s", after)
+	}
+	if _, err := fs.Stat(mem, "api/go.mod"); err != nil {
+		t.Errorf("api node not written under the root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(wd, "api")); err == nil {
+		t.Errorf("api node leaked into the working directory")
+	}
+
+	// What is written is exactly what Render plans.
+	plan, err := Render(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, f := range plan.Files {
+		want = append(want, f.Path)
+	}
+	if got := mem.Paths(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Spawn wrote %v, Render plans %v", got, want)
+	}
+}
@@ -0,0 +1,250 @@
+// Package lint checks a template tree without rendering a project: every
+// template parses, every field it references exists in the data it runs
+// against, and every file in the tree is actually used.
+package lint
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/templates"
+)
+
+// Severity separates problems that break a render from ones worth a look.
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Finding is one problem, located at a template file and, when known, a
+// line in it.
+type Finding struct {
+	Path     string // template path, including the .tmpl suffix
+	Line     int    // 0 when the finding is about the whole file
+	Severity Severity
+	Msg      string
+}
+
+func (f Finding) String() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Msg)
+	}
+	return fmt.Sprintf("%s: %s", f.Path, f.Msg)
+}
+
+// Check lints the templates for paths in fsys against a sample of the data
+// they execute with. Findings are sorted by path and line.
+func Check(fsys fs.FS, paths []string, data any) []Finding {
+	var out []Finding
+	listed := map[string]bool{}
+	for _, p := range paths {
+		name := p + templates.Ext
+		listed[name] = true
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			out = append(out, Finding{Path: name, Severity: Error, Msg: "listed for rendering but missing from the tree"})
+			continue
+		}
+		tmpl, err := render.Parse(p, string(content))
+		if err != nil {
+			out = append(out, Finding{Path: name, Line: errorLine(err), Severity: Error, Msg: err.Error()})
+			continue
+		}
+		c := &checker{tmpl: tmpl, path: name, root: reflect.ValueOf(data), invoked: map[string]bool{}}
+		c.walk(tmpl.Tree.Root, c.root)
+		for _, t := range tmpl.Templates() {
+			if t.Name() != tmpl.Name() && t.Tree != nil {
+				c.walk(t.Tree.Root, reflect.Value{})
+			}
+		}
+		for _, t := range tmpl.Templates() {
+			if t.Name() != tmpl.Name() && !c.invoked[t.Name()] {
+				c.report(Warning, nil, fmt.Sprintf("{{define %q}} is never invoked", t.Name()))
+			}
+		}
+		out = append(out, c.findings...)
+	}
+
+	fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && !listed[name] {
+			out = append(out, Finding{Path: name, Severity: Warning, Msg: "unused: never rendered"})
+		}
+		return nil
+	})
+
+	replaced, _ := templates.Overrides(fsys)
+	for _, name := range replaced {
+		out = append(out, Finding{Path: name, Severity: Warning, Msg: "shadowed: a user override replaces the built-in template"})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Line < out[j].Line
+	})
+	return out
+}
+
+// errorLine digs the line number out of a text/template parse error
+// ("template: name:LINE: ...").
+func errorLine(err error) int {
+	parts := strings.SplitN(err.Error(), ":", 5)
+	for _, p := range parts {
+		if n, convErr := strconv.Atoi(strings.TrimSpace(p)); convErr == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// checker walks a parsed template, resolving field references against the
+// sample data. An invalid dot means "unknown" (inside range, with, or a
+// {{define}}) and is not checked.
+type checker struct {
+	tmpl     *template.Template
+	path     string
+	root     reflect.Value
+	invoked  map[string]bool
+	findings []Finding
+}
+
+func (c *checker) report(sev Severity, n parse.Node, msg string) {
+	line := 0
+	if n != nil {
+		loc, _ := c.tmpl.ErrorContext(n)
+		if parts := strings.Split(loc, ":"); len(parts) >= 2 {
+			line, _ = strconv.Atoi(parts[1])
+		}
+	}
+	c.findings = append(c.findings, Finding{Path: c.path, Line: line, Severity: sev, Msg: msg})
+}
+
+func (c *checker) walk(n parse.Node, dot reflect.Value) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(child, dot)
+		}
+	case *parse.ActionNode:
+		c.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		c.branch(&n.BranchNode, dot, false)
+	case *parse.RangeNode:
+		c.branch(&n.BranchNode, dot, true)
+	case *parse.WithNode:
+		c.branch(&n.BranchNode, dot, true)
+	case *parse.TemplateNode:
+		c.invoked[n.Name] = true
+		c.pipe(n.Pipe, dot)
+	}
+}
+
+func (c *checker) branch(b *parse.BranchNode, dot reflect.Value, rebinds bool) {
+	c.pipe(b.Pipe, dot)
+	inner := dot
+	if rebinds {
+		inner = reflect.Value{}
+	}
+	c.walk(b.List, inner)
+	c.walk(b.ElseList, dot)
+}
+
+func (c *checker) pipe(p *parse.PipeNode, dot reflect.Value) {
+	if p == nil {
+		return
+	}
+	for _, cmd := range p.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.FieldNode:
+				c.field(a, dot, a.Ident)
+			case *parse.VariableNode:
+				if a.Ident[0] == "$" && len(a.Ident) > 1 {
+					c.field(a, c.root, a.Ident[1:])
+				}
+			case *parse.PipeNode:
+				c.pipe(a, dot)
+			case *parse.ChainNode:
+				if inner, ok := a.Node.(*parse.PipeNode); ok {
+					c.pipe(inner, dot)
+				}
+			}
+		}
+	}
+}
+
+func (c *checker) field(n parse.Node, dot reflect.Value, ident []string) {
+	if !dot.IsValid() {
+		return
+	}
+	if missing, in := resolve(dot, ident); missing != "" {
+		c.report(Error, n, fmt.Sprintf("%s is not in the data model (%s)", missing, in))
+	}
+}
+
+// resolve follows ident through v and returns the first reference that
+// does not exist, with the type it was looked up on, or "" if all do.
+func resolve(v reflect.Value, ident []string) (missing, in string) {
+	for i, name := range ident {
+		for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return "", ""
+			}
+			v = v.Elem()
+		}
+		if v.MethodByName(name).IsValid() {
+			return "", "" // methods are fine; their results are not followed
+		}
+		ref := "." + strings.Join(ident[:i+1], ".")
+		switch v.Kind() {
+		case reflect.Struct:
+			sf, ok := v.Type().FieldByName(name)
+			if !ok || !sf.IsExported() {
+				return ref, v.Type().String()
+			}
+			v = v.FieldByIndex(sf.Index)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return "", ""
+			}
+			e := v.MapIndex(reflect.ValueOf(name))
+			if !e.IsValid() {
+				return ref, "keys: " + strings.Join(mapKeys(v), ", ")
+			}
+			v = e
+		default:
+			return ref, v.Type().String()
+		}
+	}
+	return "", ""
+}
+
+func mapKeys(v reflect.Value) []string {
+	var keys []string
+	for _, k := range v.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
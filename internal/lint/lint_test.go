@@ -0,0 +1,41 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheck(t *testing.T) {
+	type config struct {
+		Name   string
+		WithAI bool
+	}
+	fsys := fstest.MapFS{
+		"ok.txt.tmpl":     {Data: []byte("{{.Name}}{{if .WithAI}}ai{{end}}{{range .Name}}{{.Anything}}{{end}}")},
+		"typo.txt.tmpl":   {Data: []byte("line one\n{{.ProjectNmae}}\n")},
+		"broken.txt.tmpl": {Data: []byte("{{if .Name}}")},
+		"stray.txt.tmpl":  {Data: []byte("never listed")},
+	}
+
+	findings := Check(fsys, []string{"ok.txt", "typo.txt", "broken.txt", "missing.txt"}, config{})
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Severity.String()+" "+f.String())
+	}
+	want := []string{
+		"error broken.txt.tmpl:1: parse template",
+		"error missing.txt.tmpl: listed for rendering but missing",
+		"warning stray.txt.tmpl: unused",
+		"error typo.txt.tmpl:2: .ProjectNmae is not in the data model",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d findings, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("finding %d = %q, want prefix %q", i, got[i], want[i])
+		}
+	}
+}
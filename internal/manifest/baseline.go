@@ -0,0 +1,118 @@
+package manifest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/holodanger/genesis/internal/render"
+)
+
+// BaselineName is a snapshot of every file exactly as generated. It is the
+// merge base `genesis upgrade` needs once the templates have moved on.
+const BaselineName = ".genesis/baseline.tar.gz"
+
+// Baseline packs the generated files into a deterministic tar.gz: the same
+// files always produce the same bytes.
+func Baseline(files []render.File) ([]byte, error) {
+	sorted := make([]render.File, 0, len(files))
+	for _, f := range files {
+		if !IsProvenance(f.Path) {
+			sorted = append(sorted, f)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for _, f := range sorted {
+		hdr := &tar.Header{
+			Name:     f.Path,
+			Mode:     int64(f.Mode.Perm()),
+			Size:     int64(len(f.Content)),
+			Typeflag: tar.TypeReg,
+			Format:   tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Stamp renders m and the baseline snapshot for plan, adds both to the plan
+// and returns them so the caller can write them alongside the archetype's
+// own files.
+func Stamp(plan *render.Plan, m *Manifest) ([]render.File, error) {
+	record, err := m.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := Baseline(plan.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	stamps := []render.File{
+		{Path: FileName, Content: record, Mode: 0644},
+		{Path: BaselineName, Content: baseline, Mode: 0644},
+	}
+	for _, f := range stamps {
+		plan.Set(f.Path, f.Content)
+	}
+	plan.Sort()
+	return stamps, nil
+}
+
+// ErrNoBaseline is returned by LoadBaseline when root has no snapshot.
+var ErrNoBaseline = errors.New("no " + BaselineName + " found")
+
+// LoadBaseline reads the snapshot of the project at root, keyed by path.
+func LoadBaseline(root string) (map[string][]byte, error) {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(BaselineName)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoBaseline
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", BaselineName, err)
+	}
+	tr := tar.NewReader(zr)
+
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", BaselineName, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", BaselineName, err)
+		}
+		files[hdr.Name] = data
+	}
+}
@@ -0,0 +1,97 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/holodanger/genesis/internal/render"
+)
+
+// FileName is where the manifest lives, at the root of every generated project.
+const FileName = ".genesis.json"
+
+// SpecName is the declarative spec a project was generated from, copied
+// into its root when there was one.
+const SpecName = "genesis.yaml"
+
+// IsProvenance reports whether p is one of the files that describe a
+// generation rather than being part of it. They are never hashed,
+// snapshotted, upgraded or reported as drift.
+func IsProvenance(p string) bool {
+	return p == FileName || p == BaselineName || p == SpecName
+}
+
+// Manifest records how a project was generated: by which Genesis, from which
+// archetype and options, and what every file looked like at the time.
+type Manifest struct {
+	Genesis   string            `json:"genesis"`
+	Archetype string            `json:"archetype"`
+	Template  string            `json:"template,omitempty"` // local template pack directory, if any
+	Name      string            `json:"name"`
+	Options   map[string]string `json:"options"`
+	Generated time.Time         `json:"generated"`
+	Files     map[string]string `json:"files"` // slash path -> Hash of the generated content
+}
+
+// New builds the manifest for a generation from its rendered files.
+func New(genesis, archetype, name string, options map[string]string, files []render.File) *Manifest {
+	m := &Manifest{
+		Genesis:   genesis,
+		Archetype: archetype,
+		Name:      name,
+		Options:   map[string]string{},
+		Generated: time.Now().UTC().Truncate(time.Second),
+		Files:     map[string]string{},
+	}
+	for k, v := range options {
+		m.Options[k] = v
+	}
+	for _, f := range files {
+		if IsProvenance(f.Path) {
+			continue
+		}
+		m.Files[f.Path] = Hash(f.Content)
+	}
+	return m
+}
+
+// Hash is the content fingerprint recorded for each file.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Marshal encodes the manifest as indented JSON with a trailing newline.
+func (m *Manifest) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// ErrNotFound is returned by Load when root has no manifest.
+var ErrNotFound = errors.New("no " + FileName + " found (not a Genesis project?)")
+
+// Load reads the manifest of the project at root.
+func Load(root string) (*Manifest, error) {
+	b, err := os.ReadFile(filepath.Join(root, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", FileName, err)
+	}
+	return &m, nil
+}
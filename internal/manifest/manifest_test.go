@@ -0,0 +1,71 @@
+package manifest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/holodanger/genesis/internal/render"
+)
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Load(root); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("no manifest: got %v, want ErrNotFound", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(root); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("corrupt manifest: got %v, want a parse error", err)
+	}
+
+	// What Stamp writes, Load and LoadBaseline read back.
+	plan := render.Plan{Files: []render.File{
+		{Path: "main.go", Content: []byte("package main\n"), Mode: 0644},
+		{Path: "run.sh", Content: []byte("#!/bin/sh\n"), Mode: 0755},
+		{Path: SpecName, Content: []byte("name: x\n"), Mode: 0644},
+	}}
+	m := New("v1.2.3", "go", "x", map[string]string{"ai": "true"}, plan.Files)
+	stamps, err := Stamp(&plan, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range stamps {
+		name := filepath.Join(root, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, f.Content, f.Mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Archetype != "go" || got.Options["ai"] != "true" || len(got.Files) != 2 ||
+		got.Files["run.sh"] != Hash([]byte("#!/bin/sh\n")) {
+		t.Errorf("Load = %+v", got)
+	}
+	if _, ok := got.Files[SpecName]; ok {
+		t.Errorf("the spec is provenance, not a generated file")
+	}
+
+	base, err := LoadBaseline(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(base) != 2 || string(base["main.go"]) != "package main\n" {
+		t.Errorf("LoadBaseline = %q", base)
+	}
+}
+
+func TestLoadBaselineMissing(t *testing.T) {
+	if _, err := LoadBaseline(t.TempDir()); !errors.Is(err, ErrNoBaseline) {
+		t.Errorf("got %v, want ErrNoBaseline", err)
+	}
+}
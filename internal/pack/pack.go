@@ -0,0 +1,321 @@
+// Package pack loads local template packs: directories with a manifest and
+// a template tree that behave like a built-in archetype.
+package pack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/hook"
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/templates"
+)
+
+// ManifestName is the file that makes a directory a template pack.
+const ManifestName = "genesis-pack.yaml"
+
+// TemplateDir is the directory inside a pack that holds its templates.
+const TemplateDir = "templates"
+
+// Pack is a template pack loaded from disk. It satisfies
+// archetype.Archetype, so it spawns through the same path as the built-ins.
+type Pack struct {
+	Dir string // absolute path of the pack
+	m   packManifest
+}
+
+type packManifest struct {
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description"`
+	Launch      string     `yaml:"launch"`
+	Options     []option   `yaml:"options"`
+	Files       []fileRule `yaml:"files"`
+	Commands    []cmdRule  `yaml:"commands"`
+}
+
+type option struct {
+	Name    string `yaml:"name"`
+	Kind    string `yaml:"kind"`
+	Default string `yaml:"default"`
+	Usage   string `yaml:"usage"`
+}
+
+// fileRule selects templates by glob, optionally only when a bool option
+// is set ("ai") or unset ("!ai"). A bare string is a glob with no
+// condition.
+type fileRule struct {
+	Glob string `yaml:"glob"`
+	When string `yaml:"when"`
+}
+
+func (r *fileRule) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		r.Glob = n.Value
+		return nil
+	}
+	type plain fileRule
+	return n.Decode((*plain)(r))
+}
+
+// cmdRule is a hook run in the generated project after the files are in
+// place. Run is split on spaces; there is no shell. Hook is the pipeline
+// stage (codegen when unset); install and tidy hooks need the network
+// unless Network says otherwise.
+type cmdRule struct {
+	Dir     string        `yaml:"dir"`
+	Run     string        `yaml:"run"`
+	When    string        `yaml:"when"`
+	Hook    string        `yaml:"hook"`
+	Timeout time.Duration `yaml:"timeout"`
+	Network *bool         `yaml:"network"`
+}
+
+// Load reads and checks the pack at dir.
+func Load(dir string) (*Pack, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(abs, ManifestName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%s is not a template pack (no %s)", dir, ManifestName)
+		}
+		return nil, err
+	}
+
+	p := &Pack{Dir: abs}
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p.m); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestName, err)
+	}
+	if err := p.check(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestName, err)
+	}
+	return p, nil
+}
+
+// check validates the manifest against itself and the template tree.
+func (p *Pack) check() error {
+	var errs []error
+	if p.m.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if len(p.m.Files) == 0 {
+		errs = append(errs, errors.New("files must list at least one glob"))
+	}
+
+	bools := map[string]bool{}
+	for _, o := range p.m.Options {
+		switch archetype.Kind(o.Kind) {
+		case archetype.KindBool:
+			bools[o.Name] = true
+		case archetype.KindString, archetype.KindInt:
+		default:
+			errs = append(errs, fmt.Errorf("option %q: kind must be bool, int or string, got %q", o.Name, o.Kind))
+		}
+	}
+	condition := func(where, when string) {
+		if name := strings.TrimPrefix(when, "!"); when != "" && !bools[name] {
+			errs = append(errs, fmt.Errorf("%s: when %q does not name a bool option", where, when))
+		}
+	}
+	for _, r := range p.m.Files {
+		if _, err := path.Match(strings.ReplaceAll(r.Glob, "**", "*"), ""); err != nil {
+			errs = append(errs, fmt.Errorf("files: bad glob %q", r.Glob))
+		}
+		condition("files "+r.Glob, r.When)
+	}
+	for _, c := range p.m.Commands {
+		if strings.TrimSpace(c.Run) == "" {
+			errs = append(errs, errors.New("commands: run is required"))
+		}
+		condition("commands "+c.Run, c.When)
+		if c.Hook != "" && !slices.Contains(hook.Kinds, c.Hook) {
+			errs = append(errs, fmt.Errorf("commands %s: hook must be one of %s, got %q", c.Run, strings.Join(hook.Kinds, ", "), c.Hook))
+		}
+		if c.Timeout < 0 {
+			errs = append(errs, fmt.Errorf("commands %s: timeout must be positive", c.Run))
+		}
+	}
+
+	if info, err := os.Stat(filepath.Join(p.Dir, TemplateDir)); err != nil || !info.IsDir() {
+		errs = append(errs, fmt.Errorf("missing %s/ directory", TemplateDir))
+	}
+	return errors.Join(errs...)
+}
+
+// Templates is the pack's template tree.
+func (p *Pack) Templates() fs.FS {
+	return os.DirFS(filepath.Join(p.Dir, TemplateDir))
+}
+
+func (p *Pack) Name() string        { return p.m.Name }
+func (p *Pack) Description() string { return p.m.Description }
+func (p *Pack) Launch() string      { return p.m.Launch }
+
+func (p *Pack) Options() []archetype.Option {
+	opts := make([]archetype.Option, len(p.m.Options))
+	for i, o := range p.m.Options {
+		opts[i] = archetype.Option{Name: o.Name, Kind: archetype.Kind(o.Kind), Default: o.Default, Usage: o.Usage}
+	}
+	return opts
+}
+
+// Data is what pack templates are executed against: the same project
+// options as the built-ins, plus the pack's own options. Bool and int
+// options are typed, so {{if .Options.ai}} works as expected.
+type Data struct {
+	archetype.ProjectOptions
+	Options map[string]any
+}
+
+func (p *Pack) data(proj archetype.Project) Data {
+	d := Data{ProjectOptions: proj.ProjectOptions(), Options: map[string]any{}}
+	for _, o := range p.m.Options {
+		switch archetype.Kind(o.Kind) {
+		case archetype.KindBool:
+			d.Options[o.Name] = proj.Options.Bool(o.Name)
+		case archetype.KindInt:
+			d.Options[o.Name] = proj.Options.Int(o.Name)
+		default:
+			d.Options[o.Name] = proj.Options.String(o.Name)
+		}
+	}
+	return d
+}
+
+// active reports whether a rule's condition holds for the project.
+func active(when string, values archetype.Values) bool {
+	if when == "" {
+		return true
+	}
+	if name, negated := strings.CutPrefix(when, "!"); negated {
+		return !values.Bool(name)
+	}
+	return values.Bool(when)
+}
+
+// Paths lists the output paths the pack renders for proj: every template
+// matched by an active file rule.
+func (p *Pack) Paths(proj archetype.Project) ([]string, error) {
+	return p.paths(func(r fileRule) bool { return active(r.When, proj.Options) })
+}
+
+// TemplateSet lists every template any rule can select, whatever the
+// options, with data that has every option set.
+func (p *Pack) TemplateSet() ([]string, any) {
+	paths, _ := p.paths(func(fileRule) bool { return true })
+	values := archetype.Values{}
+	for _, o := range p.m.Options {
+		values[o.Name] = o.Default
+		if archetype.Kind(o.Kind) == archetype.KindBool {
+			values[o.Name] = "true"
+		}
+	}
+	return paths, p.data(archetype.Project{Name: "lint", Options: values})
+}
+
+func (p *Pack) paths(use func(fileRule) bool) ([]string, error) {
+	var paths []string
+	err := fs.WalkDir(p.Templates(), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(name, templates.Ext) {
+			return err
+		}
+		out := strings.TrimSuffix(name, templates.Ext)
+		for _, r := range p.m.Files {
+			if use(r) && match(r.Glob, out) {
+				paths = append(paths, out)
+				break
+			}
+		}
+		return nil
+	})
+	return paths, err
+}
+
+func (p *Pack) Plan(proj archetype.Project) (render.Plan, error) {
+	paths, err := p.Paths(proj)
+	if err != nil {
+		return render.Plan{}, err
+	}
+	files, err := render.Tree(p.Templates(), paths, p.data(proj))
+	if err != nil {
+		return render.Plan{}, err
+	}
+
+	plan := render.Plan{Files: files}
+	for _, c := range p.m.Commands {
+		if !active(c.When, proj.Options) {
+			continue
+		}
+		args := strings.Fields(c.Run)
+		cmd := render.Command{Dir: path.Clean("/" + c.Dir)[1:], Name: args[0], Args: args[1:], Hook: c.Hook, Timeout: c.Timeout}
+		if cmd.Hook == "" {
+			cmd.Hook = hook.Codegen
+		}
+		cmd.Network = cmd.Hook == hook.Install || cmd.Hook == hook.Tidy
+		if c.Network != nil {
+			cmd.Network = *c.Network
+		}
+		plan.Commands = append(plan.Commands, cmd)
+	}
+	return plan, nil
+}
+
+func (p *Pack) Generate(ctx context.Context, proj archetype.Project, w *render.Writer) error {
+	report := progress.From(ctx)
+	report.Step("[PACK] Rendering %s from %s...", p.m.Name, p.Dir)
+	plan, err := p.Plan(proj)
+	if err != nil {
+		return fmt.Errorf("pack: %w", err)
+	}
+	for _, f := range plan.Files {
+		if err := w.WriteFile(filepath.Join(proj.Root, f.Path), f.Content, f.Mode); err != nil {
+			return fmt.Errorf("pack: write %w", err)
+		}
+		report.File(f.Path)
+	}
+	return nil
+}
+
+// match reports whether the slash path name matches glob, where "**"
+// matches any number of path segments and other segments follow
+// path.Match.
+func match(glob, name string) bool {
+	return matchSegments(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(glob, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(glob[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], name[0]); !ok {
+			return false
+		}
+		glob, name = glob[1:], name[1:]
+	}
+	return len(name) == 0
+}
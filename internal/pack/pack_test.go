@@ -0,0 +1,95 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/holodanger/genesis/internal/archetype"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		glob, name string
+		want       bool
+	}{
+		{"**", "a/b/c.go", true},
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"cmd/**", "cmd/api/main.go", true},
+		{"cmd/**/main.go", "cmd/main.go", true},
+		{"internal/ai/*", "internal/db/x.go", false},
+	}
+	for _, tc := range cases {
+		if got := match(tc.glob, tc.name); got != tc.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tc.glob, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(ManifestName, `name: svc
+options:
+  - {name: ai, kind: bool, default: "false"}
+files:
+  - README.md
+  - glob: ai/**
+    when: ai
+commands:
+  - run: go mod tidy
+    hook: tidy
+    timeout: 90s
+  - run: make gen
+`)
+	write("templates/README.md.tmpl", "# {{.Name}}{{if .Options.ai}} (ai){{end}}\n")
+	write("templates/ai/client.go.tmpl", "package ai\n")
+	write("templates/unlisted.txt.tmpl", "nope\n")
+
+	p, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := archetype.Resolve(p, map[string]string{"ai": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := p.Plan(archetype.Project{Name: "demo", Options: values})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Files) != 2 || plan.Files[0].Path != "README.md" || plan.Files[1].Path != "ai/client.go" {
+		t.Fatalf("files = %+v", plan.Files)
+	}
+	if got := string(plan.Files[0].Content); got != "# demo (ai)\n" {
+		t.Errorf("README = %q", got)
+	}
+	if len(plan.Commands) != 2 || plan.Commands[0].String() != "go mod tidy" {
+		t.Fatalf("commands = %+v", plan.Commands)
+	}
+	if c := plan.Commands[0]; c.Hook != "tidy" || c.Timeout != 90*time.Second || !c.Network {
+		t.Errorf("tidy hook = %+v", c)
+	}
+	if c := plan.Commands[1]; c.Hook != "codegen" || c.Network {
+		t.Errorf("default hook = %+v", c)
+	}
+}
+
+func TestLoadRejectsBadManifest(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ManifestName), []byte("name: x\nfiles: ['**']\ncommands:\n  - run: make\n    when: missing\n"), 0644)
+	if _, err := Load(dir); err == nil {
+		t.Fatal("expected an error for an unknown condition and a missing templates/ dir")
+	}
+}
@@ -0,0 +1,134 @@
+// Package progress carries what a generation is doing to whoever drives
+// it. Builders report through the context; the CLI prints the events, a
+// library caller receives them as callbacks. Without a Func on the context
+// nothing is reported.
+package progress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Kind says what an Event reports.
+type Kind int
+
+const (
+	// Step is a phase of the generation starting; Msg describes it.
+	Step Kind = iota
+	// File is a file written; Path is relative to the node.
+	File
+	// Command is an external command starting; Msg is the command line and
+	// Path the directory it runs in.
+	Command
+	// Output is one line an external command printed, in Msg.
+	Output
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Step:
+		return "step"
+	case File:
+		return "file"
+	case Command:
+		return "command"
+	case Output:
+		return "output"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Event is one thing that happened during a generation.
+type Event struct {
+	Kind Kind
+	Node string // sub-project the event belongs to ("web", "api"); "" for the project itself
+	Path string
+	Msg  string
+}
+
+// Func receives events. It may be called from several goroutines at once.
+type Func func(Event)
+
+type ctxKey struct{}
+
+type reporter struct {
+	f    Func
+	node string
+}
+
+// With returns a context whose generation reports to f.
+func With(ctx context.Context, f Func) context.Context {
+	return context.WithValue(ctx, ctxKey{}, reporter{f: f})
+}
+
+// WithNode returns a context whose events are attributed to node.
+func WithNode(ctx context.Context, node string) context.Context {
+	r, _ := ctx.Value(ctxKey{}).(reporter)
+	r.node = node
+	return context.WithValue(ctx, ctxKey{}, r)
+}
+
+// From returns the Func to report to from ctx, with the node filled in. It
+// is never nil.
+func From(ctx context.Context) Func {
+	r, _ := ctx.Value(ctxKey{}).(reporter)
+	if r.f == nil {
+		return func(Event) {}
+	}
+	return func(e Event) {
+		if e.Node == "" {
+			e.Node = r.node
+		}
+		r.f(e)
+	}
+}
+
+// Step reports the start of a phase.
+func (f Func) Step(format string, args ...any) {
+	f(Event{Kind: Step, Msg: fmt.Sprintf(format, args...)})
+}
+
+// File reports a written file.
+func (f Func) File(path string) {
+	f(Event{Kind: File, Path: path})
+}
+
+// Lines returns a writer that reports every line written to it as an
+// Output event. Close flushes a final line without a newline.
+func (f Func) Lines() io.WriteCloser {
+	return &lineWriter{f: f}
+}
+
+type lineWriter struct {
+	mu  sync.Mutex
+	f   Func
+	buf []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.f(Event{Kind: Output, Msg: string(bytes.TrimRight(w.buf[:i], "\r"))})
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.f(Event{Kind: Output, Msg: string(w.buf)})
+		w.buf = nil
+	}
+	return nil
+}
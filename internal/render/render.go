@@ -0,0 +1,135 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/holodanger/genesis/internal/templates"
+)
+
+// File is a rendered file held in memory.
+type File struct {
+	Path    string // slash-separated, relative to the project root
+	Content []byte
+	Mode    fs.FileMode
+}
+
+// Command is an external command a generation runs after writing files:
+// one hook of the post-generation pipeline.
+type Command struct {
+	Dir     string // slash-separated, relative to the project root ("" is the root)
+	Name    string
+	Args    []string
+	Hook    string        // pipeline stage: "install", "tidy", "format", "git" or "codegen"
+	Timeout time.Duration // 0 uses the stage's default
+	Network bool          // needs the network; skipped offline
+	Env     []string      // added to the environment, as KEY=value
+}
+
+// String is the command line, with arguments that contain spaces quoted.
+func (c Command) String() string {
+	words := []string{c.Name}
+	for _, a := range c.Args {
+		if strings.ContainsAny(a, " \t") {
+			a = strconv.Quote(a)
+		}
+		words = append(words, a)
+	}
+	return strings.Join(words, " ")
+}
+
+// Plan is everything a generation would do: the files it writes and the
+// commands it runs afterwards.
+type Plan struct {
+	Files    []File
+	Commands []Command
+}
+
+// Merge folds a sub-plan into p, re-rooting its files and commands under dir.
+func (p *Plan) Merge(dir string, sub Plan) {
+	for _, f := range sub.Files {
+		f.Path = path.Join(dir, f.Path)
+		p.Files = append(p.Files, f)
+	}
+	for _, c := range sub.Commands {
+		c.Dir = path.Join(dir, c.Dir)
+		p.Commands = append(p.Commands, c)
+	}
+}
+
+// Set replaces the content of the file at name, adding it if absent.
+func (p *Plan) Set(name string, content []byte) {
+	for i := range p.Files {
+		if p.Files[i].Path == name {
+			p.Files[i].Content = content
+			return
+		}
+	}
+	p.Files = append(p.Files, File{Path: name, Content: content, Mode: 0644})
+}
+
+// Sort orders the files by path so output is deterministic.
+func (p *Plan) Sort() {
+	sort.Slice(p.Files, func(i, j int) bool { return p.Files[i].Path < p.Files[j].Path })
+}
+
+// Parse parses content as a text/template called name, with the options
+// every Genesis template is rendered under.
+func Parse(name, content string) (*template.Template, error) {
+	// A misspelled key must fail the render, not emit "<no value>".
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Execute parses content as a text/template called name and executes it
+// against data.
+func Execute(name, content string, data any) ([]byte, error) {
+	tmpl, err := Parse(name, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("execute template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Tree renders the templates for paths out of fsys against data, sorted
+// by path. Each output path is read from its template, path+".tmpl". Go
+// sources come out gofmt'd: conditional blocks leave stray blank lines and
+// misaligned fields that a format hook would otherwise rewrite, making a
+// fresh project look edited.
+func Tree(fsys fs.FS, paths []string, data any) ([]File, error) {
+	out := make([]File, 0, len(paths))
+	for _, name := range paths {
+		content, err := fs.ReadFile(fsys, name+templates.Ext)
+		if err != nil {
+			return nil, fmt.Errorf("load template %s: %w", name, err)
+		}
+		b, err := Execute(name, string(content), data)
+		if err != nil {
+			return nil, err
+		}
+		if path.Ext(name) == ".go" {
+			if b, err = format.Source(b); err != nil {
+				return nil, fmt.Errorf("template %s: invalid Go: %w", name, err)
+			}
+		}
+		out = append(out, File{Path: name, Content: b, Mode: 0644})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/vfs"
+)
+
+// Writer puts generated files into an FS. Builders address files by their
+// final location under Root; the bytes land at the same root-relative path
+// in FS, which may be the target on disk, a stage, memory or an archive.
+// Files the user chose to keep are left untouched and recorded in Kept.
+//
+// A Writer may be shared by builders running concurrently.
+type Writer struct {
+	Root string
+	FS   vfs.FS
+	keep map[string]bool
+
+	mu   sync.Mutex
+	Kept []string
+}
+
+// NewWriter returns a Writer for root that writes into fsys and will not
+// touch the slash-separated, root-relative paths in keep.
+func NewWriter(root string, fsys vfs.FS, keep []string) *Writer {
+	w := &Writer{Root: root, FS: fsys, keep: map[string]bool{}}
+	for _, p := range keep {
+		w.keep[p] = true
+	}
+	return w
+}
+
+// WriteFile writes data to name, which may be absolute or relative to the
+// working directory but must resolve inside Root. Errors name the file.
+func (w *Writer) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	rel, err := filepath.Rel(w.Root, abs)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("%s is outside the project root %s", name, w.Root)
+	}
+
+	slashed := filepath.ToSlash(rel)
+	if w.keep[slashed] {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.Kept = append(w.Kept, slashed)
+		sort.Strings(w.Kept)
+		return nil
+	}
+
+	if err := w.FS.WriteFile(slashed, data, perm); err != nil {
+		return fmt.Errorf("%s: %w", slashed, err)
+	}
+	return nil
+}
+
+// WriteFiles writes rendered files under dir, in order, reporting each
+// through the progress Func on ctx. It stops once ctx is cancelled.
+func (w *Writer) WriteFiles(ctx context.Context, dir string, files []File) error {
+	report := progress.From(ctx)
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := w.WriteFile(filepath.Join(dir, filepath.FromSlash(f.Path)), f.Content, f.Mode); err != nil {
+			return err
+		}
+		report.File(f.Path)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/holodanger/genesis/internal/progress"
+)
+
+// Run executes name with args inside dir. The command and every line it
+// prints are reported through the progress Func on ctx. A failure carries
+// the last line the command printed, which is usually the reason.
+func Run(ctx context.Context, dir string, name string, args ...string) error {
+	return RunEnv(ctx, dir, nil, name, args...)
+}
+
+// RunEnv is Run with env, as KEY=value, added to the environment.
+func RunEnv(ctx context.Context, dir string, env []string, name string, args ...string) error {
+	report := progress.From(ctx)
+	report(progress.Event{Kind: progress.Command, Path: dir, Msg: strings.Join(append([]string{name}, args...), " ")})
+
+	var last string
+	out := progress.Func(func(e progress.Event) {
+		if strings.TrimSpace(e.Msg) != "" {
+			last = strings.TrimSpace(e.Msg)
+		}
+		report(e)
+	}).Lines()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	out.Close()
+	if err != nil && last != "" {
+		return fmt.Errorf("%w: %s", err, last)
+	}
+	return err
+}
@@ -0,0 +1,173 @@
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/holodanger/genesis/internal/archetype"
+)
+
+// kind is the YAML shape a schema node expects.
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindList
+	kindMap    // fixed set of keys
+	kindStrMap // arbitrary string keys, string values
+)
+
+// node is one level of the spec schema.
+type node struct {
+	kind     kind
+	required bool
+	enum     []string
+	min, max int // inclusive bounds for kindInt (ignored when both zero)
+	items    *node
+	fields   map[string]*node
+}
+
+// schema describes genesis.yaml.
+var schema = &node{kind: kindMap, fields: map[string]*node{
+	"version":   {kind: kindInt, min: 1, max: 1},
+	"name":      {kind: kindString, required: true},
+	"archetype": {kind: kindString, required: true},
+	"module":    {kind: kindString},
+	"database": {kind: kindMap, fields: map[string]*node{
+		"name": {kind: kindString},
+	}},
+	"auth": {kind: kindMap, fields: map[string]*node{
+		"providers": {kind: kindList, items: &node{kind: kindString, enum: archetype.AuthProviders}},
+	}},
+	"ai": {kind: kindMap, fields: map[string]*node{
+		"provider": {kind: kindString, enum: []string{"none", "openai"}},
+	}},
+	"ports": {kind: kindMap, fields: map[string]*node{
+		"web": {kind: kindInt, min: 1, max: 65535},
+		"api": {kind: kindInt, min: 1, max: 65535},
+	}},
+	"options": {kind: kindStrMap},
+}}
+
+// check validates y against n, appending one line-numbered problem per
+// violation so the user sees them all at once.
+func (n *node) check(path string, y *yaml.Node, errs *[]lineError) {
+	fail := func(format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		if path != "" {
+			msg = path + ": " + msg
+		}
+		*errs = append(*errs, lineError{line: y.Line, col: y.Column, msg: msg})
+	}
+
+	switch n.kind {
+	case kindString:
+		if y.Kind != yaml.ScalarNode || y.Tag != "!!str" {
+			fail("expected a string")
+			return
+		}
+		if len(n.enum) > 0 && !contains(n.enum, y.Value) {
+			fail("%q is not one of %s", y.Value, strings.Join(n.enum, ", "))
+		}
+
+	case kindInt:
+		if y.Kind != yaml.ScalarNode || y.Tag != "!!int" {
+			fail("expected an integer")
+			return
+		}
+		v, err := strconv.Atoi(y.Value)
+		if err != nil || (n.min != 0 || n.max != 0) && (v < n.min || v > n.max) {
+			fail("%s is out of range %d-%d", y.Value, n.min, n.max)
+		}
+
+	case kindList:
+		if y.Kind != yaml.SequenceNode {
+			fail("expected a list")
+			return
+		}
+		for i, item := range y.Content {
+			n.items.check(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+
+	case kindStrMap:
+		if y.Kind != yaml.MappingNode {
+			fail("expected a mapping")
+			return
+		}
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			v := y.Content[i+1]
+			if v.Kind != yaml.ScalarNode {
+				(&node{kind: kindString}).check(join(path, y.Content[i].Value), v, errs)
+			}
+		}
+
+	case kindMap:
+		if y.Kind != yaml.MappingNode {
+			fail("expected a mapping")
+			return
+		}
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			k, v := y.Content[i], y.Content[i+1]
+			field, ok := n.fields[k.Value]
+			if !ok {
+				*errs = append(*errs, lineError{line: k.Line, col: k.Column,
+					msg: fmt.Sprintf("%s: unknown field (expected one of %s)", join(path, k.Value), strings.Join(n.keys(), ", "))})
+				continue
+			}
+			seen[k.Value] = true
+			field.check(join(path, k.Value), v, errs)
+		}
+		for _, name := range n.keys() {
+			if n.fields[name].required && !seen[name] {
+				fail("missing required field %q", name)
+			}
+		}
+	}
+}
+
+func (n *node) keys() []string {
+	keys := make([]string, 0, len(n.fields))
+	for k := range n.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Choices returns the allowed values of a dotted field (for lists, of its
+// items), or nil if the field is free-form.
+func Choices(field string) []string {
+	n := schema
+	for _, part := range strings.Split(field, ".") {
+		if n.fields[part] == nil {
+			return nil
+		}
+		n = n.fields[part]
+	}
+	if n.items != nil {
+		n = n.items
+	}
+	return n.enum
+}
@@ -0,0 +1,216 @@
+package spec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/manifest"
+)
+
+// Spec is a declarative description of a project (genesis.yaml).
+type Spec struct {
+	Version   int               `yaml:"version"`
+	Name      string            `yaml:"name"`
+	Archetype string            `yaml:"archetype"`
+	Module    string            `yaml:"module"`
+	Database  Database          `yaml:"database"`
+	Auth      Auth              `yaml:"auth"`
+	AI        AI                `yaml:"ai"`
+	Ports     Ports             `yaml:"ports"`
+	Options   map[string]string `yaml:"options"`
+
+	file string
+	keys map[string]*yaml.Node // dotted field path -> its key, for positions
+}
+
+type Database struct {
+	Name string `yaml:"name"`
+}
+
+type Auth struct {
+	Providers []string `yaml:"providers"`
+}
+
+type AI struct {
+	Provider string `yaml:"provider"`
+}
+
+type Ports struct {
+	Web int `yaml:"web"`
+	API int `yaml:"api"`
+}
+
+// lineError is a single problem at a position in the spec.
+type lineError struct {
+	line, col int
+	msg       string
+}
+
+// Error lists every problem found in a spec, each prefixed with
+// file:line:col.
+type Error struct {
+	File     string
+	problems []lineError
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d problem(s)", e.File, len(e.problems))
+	for _, p := range e.problems {
+		fmt.Fprintf(&b, "\n    %s:%d:%d: %s", e.File, p.line, p.col, p.msg)
+	}
+	return b.String()
+}
+
+// Parse validates data against the spec schema and decodes it. file is
+// only used to label errors.
+func Parse(file string, data []byte) (*Spec, error) {
+	var doc yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &Error{File: file, problems: []lineError{{line: 1, col: 1, msg: "empty spec"}}}
+		}
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	root := doc.Content[0]
+
+	var problems []lineError
+	schema.check("", root, &problems)
+	if len(problems) > 0 {
+		sort.SliceStable(problems, func(i, j int) bool { return problems[i].line < problems[j].line })
+		return nil, &Error{File: file, problems: problems}
+	}
+
+	s := &Spec{Version: 1, file: file, keys: map[string]*yaml.Node{}}
+	if err := root.Decode(s); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	record(root, "", s.keys)
+	return s, nil
+}
+
+// record notes the key node of every mapping entry by dotted path.
+func record(y *yaml.Node, path string, keys map[string]*yaml.Node) {
+	if y.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(y.Content); i += 2 {
+		p := join(path, y.Content[i].Value)
+		keys[p] = y.Content[i]
+		record(y.Content[i+1], p, keys)
+	}
+}
+
+// setting maps one spec field onto an archetype option.
+type setting struct {
+	field  string // dotted spec path, for errors
+	option string
+	value  string
+}
+
+// Values resolves the spec into option values for a, reporting settings
+// the archetype does not support at the line they were written.
+func (s *Spec) Values(a archetype.Archetype) (archetype.Values, error) {
+	var settings []setting
+	add := func(field, option, value string) {
+		settings = append(settings, setting{field: field, option: option, value: value})
+	}
+
+	if s.Module != "" {
+		add("module", "module", s.Module)
+	}
+	if s.Database.Name != "" {
+		add("database.name", "db-name", s.Database.Name)
+	}
+	if s.Ports.Web != 0 {
+		add("ports.web", "web-port", strconv.Itoa(s.Ports.Web))
+	}
+	if s.Ports.API != 0 {
+		add("ports.api", "api-port", strconv.Itoa(s.Ports.API))
+	}
+	switch s.AI.Provider {
+	case "openai":
+		add("ai.provider", "ai", "true")
+	case "none":
+		add("ai.provider", "ai", "false")
+	}
+	if len(s.Auth.Providers) > 0 {
+		add("auth.providers", "auth", strings.Join(s.Auth.Providers, ","))
+	}
+	for k, v := range s.Options {
+		add("options."+k, k, v)
+	}
+
+	declared := map[string]archetype.Option{}
+	for _, opt := range a.Options() {
+		declared[opt.Name] = opt
+	}
+
+	raw := map[string]string{}
+	var problems []lineError
+	for _, st := range settings {
+		opt, ok := declared[st.option]
+		if !ok {
+			problems = append(problems, s.errorAt(st.field,
+				fmt.Sprintf("%s: not supported by archetype %s (no option %q)", st.field, a.Name(), st.option)))
+			continue
+		}
+		if opt.Check != nil && st.value != "" {
+			if err := opt.Check(st.value); err != nil {
+				problems = append(problems, s.errorAt(st.field, fmt.Sprintf("%s: %v", st.field, err)))
+				continue
+			}
+		}
+		if prev, dup := raw[st.option]; dup && prev != st.value {
+			problems = append(problems, s.errorAt(st.field,
+				fmt.Sprintf("%s: contradicts an earlier setting of option %q", st.field, st.option)))
+			continue
+		}
+		raw[st.option] = st.value
+	}
+	if len(problems) > 0 {
+		sort.SliceStable(problems, func(i, j int) bool { return problems[i].line < problems[j].line })
+		return nil, &Error{File: s.File(), problems: problems}
+	}
+
+	values, err := archetype.Resolve(a, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.File(), err)
+	}
+	return values, nil
+}
+
+// File is the name the spec was parsed from, or "genesis.yaml" for a spec
+// built in code.
+func (s *Spec) File() string {
+	if s.file == "" {
+		return manifest.SpecName
+	}
+	return s.file
+}
+
+// Line returns the line a dotted field was written on (0 if absent).
+func (s *Spec) Line(field string) int {
+	if k, ok := s.keys[field]; ok {
+		return k.Line
+	}
+	return 0
+}
+
+// errorAt places msg at the key of field, or at the top of a spec built
+// in code.
+func (s *Spec) errorAt(field, msg string) lineError {
+	if k, ok := s.keys[field]; ok {
+		return lineError{line: k.Line, col: k.Column, msg: msg}
+	}
+	return lineError{line: 1, col: 1, msg: msg}
+}
@@ -0,0 +1,86 @@
+package spec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/goservice"
+	"github.com/holodanger/genesis/internal/t3"
+)
+
+func TestParseReportsEveryProblemWithPosition(t *testing.T) {
+	src := `name: demo
+archetype: go
+ports:
+  api: 70000
+ai:
+  provider: anthropic
+colour: blue
+`
+	_, err := Parse("genesis.yaml", []byte(src))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"genesis.yaml:4:8: ports.api: 70000 is out of range 1-65535",
+		`genesis.yaml:6:13: ai.provider: "anthropic" is not one of none, openai`,
+		"genesis.yaml:7:1: colour: unknown field",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestParseRequiresNameAndArchetype(t *testing.T) {
+	_, err := Parse("genesis.yaml", []byte("version: 1\n"))
+	if err == nil || !strings.Contains(err.Error(), "missing required field") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func TestParseDecodes(t *testing.T) {
+	s, err := Parse("genesis.yaml", []byte("name: demo\narchetype: hybrid\nai:\n  provider: openai\nauth:\n  providers: [email, github]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "demo" || s.Archetype != "hybrid" || s.AI.Provider != "openai" || len(s.Auth.Providers) != 2 {
+		t.Fatalf("decoded %+v", s)
+	}
+	if s.Line("ai.provider") != 4 {
+		t.Errorf("ai.provider line = %d, want 4", s.Line("ai.provider"))
+	}
+}
+
+func TestValuesCarriesAuth(t *testing.T) {
+	s, err := Parse("genesis.yaml", []byte("name: demo\narchetype: t3\nauth:\n  providers: [email, github]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := s.Values(t3.Archetype{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := archetype.Project{Name: "demo", Options: values}.ProjectOptions()
+	if !o.Auth.Has("github") || !o.Auth.Has("email") {
+		t.Errorf("auth providers = %v, want email and github", o.Auth.Providers)
+	}
+
+	// The go archetype has no sign-in, so the setting is an error there.
+	_, err = s.Values(goservice.Archetype{})
+	if err == nil || !strings.Contains(err.Error(), "genesis.yaml:4:3: auth.providers: not supported by archetype go") {
+		t.Errorf("go archetype: got %v", err)
+	}
+}
+
+func TestValuesChecksDBName(t *testing.T) {
+	s, err := Parse("genesis.yaml", []byte("name: demo\narchetype: go\ndatabase:\n  name: 'my db\"x'\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Values(goservice.Archetype{})
+	if err == nil || !strings.Contains(err.Error(), "genesis.yaml:4:3: database.name: database name") {
+		t.Errorf("got %v, want the database name rejected at its line", err)
+	}
+}
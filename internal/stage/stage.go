@@ -0,0 +1,228 @@
+package stage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/vfs"
+)
+
+// Stage is a scratch directory a generation renders into. Nothing reaches
+// the real target until Commit; Rollback leaves the target as it was.
+type Stage struct {
+	Root string // final destination
+	Dir  string // scratch directory, a sibling of Root so renames stay atomic
+
+	rootExisted bool
+	committed   bool
+}
+
+// Begin opens a stage for root.
+func Begin(root string) (*Stage, error) {
+	info, err := os.Stat(root)
+	existed := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	parent := filepath.Dir(root)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(parent, "."+filepath.Base(root)+".genesis-stage-")
+	if err != nil {
+		return nil, fmt.Errorf("open stage: %w", err)
+	}
+	// MkdirTemp makes the stage private (0700), and Commit may rename it
+	// into place as the project root: give it the mode of the root it
+	// replaces, or that of any other directory.
+	mode := fs.FileMode(0755)
+	if existed {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(dir, mode); err != nil {
+		os.Remove(dir)
+		return nil, fmt.Errorf("open stage: %w", err)
+	}
+	return &Stage{Root: root, Dir: dir, rootExisted: existed}, nil
+}
+
+// Writer returns a Writer that addresses files under Root but lands them
+// in the stage, leaving the paths in keep untouched.
+func (s *Stage) Writer(keep []string) *render.Writer {
+	return render.NewWriter(s.Root, vfs.OS{Dir: s.Dir}, keep)
+}
+
+// Validate checks the staged output against the plan; see Verify.
+func (s *Stage) Validate(plan render.Plan, keep []string) error {
+	return Verify(os.DirFS(s.Dir), plan, keep)
+}
+
+// Verify checks generated output in fsys against the plan: every planned
+// file must be present, Go sources must parse and JSON files must be well
+// formed.
+func Verify(fsys fs.FS, plan render.Plan, keep []string) error {
+	kept := map[string]bool{}
+	for _, p := range keep {
+		kept[p] = true
+	}
+
+	fset := token.NewFileSet()
+	for _, f := range plan.Files {
+		if kept[f.Path] {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, f.Path)
+		if err != nil {
+			return fmt.Errorf("%s: not generated: %w", f.Path, err)
+		}
+
+		switch path.Ext(f.Path) {
+		case ".go":
+			if _, err := parser.ParseFile(fset, f.Path, data, parser.AllErrors); err != nil {
+				return fmt.Errorf("%s: invalid Go: %w", f.Path, err)
+			}
+		case ".json":
+			if !json.Valid(bytes.TrimSpace(data)) {
+				return fmt.Errorf("%s: invalid JSON", f.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// Commit moves the staged files into Root. A missing or empty Root is
+// replaced in a single rename; otherwise files are moved one by one and
+// any failure puts back what was already moved.
+func (s *Stage) Commit() error {
+	if empty, err := isEmptyDir(s.Root); err != nil {
+		return err
+	} else if empty {
+		if err := os.Remove(s.Root); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.Rename(s.Dir, s.Root); err != nil {
+			return fmt.Errorf("move stage into place: %w", err)
+		}
+		s.committed = true
+		return nil
+	}
+
+	files, err := listFiles(s.Dir)
+	if err != nil {
+		return err
+	}
+
+	backup := s.Dir + ".bak"
+	var undo []func()
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	for _, rel := range files {
+		src := filepath.Join(s.Dir, rel)
+		dst := filepath.Join(s.Root, rel)
+
+		created, err := mkdirAll(filepath.Dir(dst))
+		if err != nil {
+			rollback()
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+		if created != "" {
+			undo = append(undo, func() { os.RemoveAll(created) })
+		}
+
+		if _, err := os.Lstat(dst); err == nil {
+			saved := filepath.Join(backup, rel)
+			if err := os.MkdirAll(filepath.Dir(saved), 0755); err != nil {
+				rollback()
+				return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+			}
+			if err := os.Rename(dst, saved); err != nil {
+				rollback()
+				return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+			}
+			undo = append(undo, func() { os.Rename(saved, dst) })
+		}
+
+		if err := os.Rename(src, dst); err != nil {
+			rollback()
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+		undo = append(undo, func() { os.Rename(dst, src) })
+	}
+
+	s.committed = true
+	os.RemoveAll(backup)
+	os.RemoveAll(s.Dir)
+	return nil
+}
+
+// Rollback discards the stage. Before Commit it also removes Root if Begin
+// found it missing and it has stayed empty; after Commit it is a no-op.
+func (s *Stage) Rollback() {
+	if s.committed {
+		return
+	}
+	os.RemoveAll(s.Dir)
+	// A backup that still holds files means a restore failed; keep it so
+	// nothing of the user's is lost.
+	if files, _ := listFiles(s.Dir + ".bak"); len(files) == 0 {
+		os.RemoveAll(s.Dir + ".bak")
+	}
+	if !s.rootExisted {
+		os.Remove(s.Root) // only succeeds while empty
+	}
+}
+
+// listFiles returns the regular files under dir, relative to it.
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		files = append(files, rel)
+		return err
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// isEmptyDir reports whether dir is missing or has no entries.
+func isEmptyDir(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	return len(entries) == 0, err
+}
+
+// mkdirAll is os.MkdirAll that also returns the top-most directory it had
+// to create ("" if dir already existed).
+func mkdirAll(dir string) (string, error) {
+	top := ""
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		top = d
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return top, os.MkdirAll(dir, 0755)
+}
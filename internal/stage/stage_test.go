@@ -0,0 +1,115 @@
+package stage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCommitRollsBack forces a commit to fail halfway and checks that the
+// target is left exactly as it was.
+func TestCommitRollsBack(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "node")
+	mustWrite(t, filepath.Join(root, "Makefile"), "old")
+	mustWrite(t, filepath.Join(root, "web"), "a file where a directory is needed")
+
+	st, err := Begin(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := st.Writer(nil)
+	for _, name := range []string{"Makefile", "api/go.mod", "web/package.json"} {
+		if err := w.WriteFile(filepath.Join(root, name), []byte("new"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := st.Commit(); err == nil {
+		t.Fatal("Commit succeeded, want failure on web/package.json")
+	}
+	st.Rollback()
+
+	if got := mustRead(t, filepath.Join(root, "Makefile")); got != "old" {
+		t.Errorf("Makefile = %q after rollback, want %q", got, "old")
+	}
+	if _, err := os.Stat(filepath.Join(root, "api")); !os.IsNotExist(err) {
+		t.Errorf("api/ survived rollback (err=%v)", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(root))
+	if len(entries) != 1 {
+		t.Errorf("stage debris left next to the target: %v", entries)
+	}
+}
+
+// TestRollbackRemovesFreshRoot checks that a failed generation into a new
+// directory leaves nothing behind.
+func TestRollbackRemovesFreshRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "node")
+
+	st, err := Begin(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Writer(nil).WriteFile(filepath.Join(root, "go.mod"), []byte("module node\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st.Rollback()
+
+	entries, _ := os.ReadDir(filepath.Dir(root))
+	if len(entries) != 0 {
+		t.Errorf("rollback left %v behind", entries)
+	}
+}
+
+func mustWrite(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustRead(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// TestCommitRootMode checks that a root committed in one rename gets a
+// directory's usual mode, or keeps that of the empty root it replaces,
+// not the stage's private one.
+func TestCommitRootMode(t *testing.T) {
+	fresh := filepath.Join(t.TempDir(), "fresh")
+	empty := filepath.Join(t.TempDir(), "empty")
+	if err := os.Mkdir(empty, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(empty, 0750); err != nil {
+		t.Fatal(err)
+	}
+
+	for root, want := range map[string]os.FileMode{fresh: 0755, empty: 0750} {
+		st, err := Begin(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := st.Writer(nil).WriteFile(filepath.Join(root, "go.mod"), []byte("module node\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.Commit(); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s: mode %v, want %v", filepath.Base(root), got, want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package t3
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/render"
+)
+
+func init() {
+	archetype.Register(Archetype{})
+}
+
+// Archetype registers the standalone Next.js node ("Frontend Shield").
+type Archetype struct{}
+
+func (Archetype) Name() string { return "t3" }
+
+func (Archetype) Description() string {
+	return "Frontend Shield: Next.js 16 + Drizzle + Better Auth"
+}
+
+func (Archetype) Options() []archetype.Option {
+	return []archetype.Option{
+		archetype.OptDBName,
+		archetype.OptAuth,
+		archetype.OptWebPort,
+	}
+}
+
+func (Archetype) Launch() string { return "bun dev" }
+
+func (Archetype) Plan(p archetype.Project) (render.Plan, error) {
+	return Render(p.ProjectOptions())
+}
+
+func (Archetype) Generate(ctx context.Context, p archetype.Project, w *render.Writer) error {
+	return Spawn(ctx, w, p.Root, p.ProjectOptions())
+}
+
+func (Archetype) Templates() fs.FS { return Templates() }
+
+func (Archetype) TemplateSet() ([]string, any) {
+	o := archetype.Project{Name: "lint"}.ProjectOptions()
+	o.Hybrid = true
+	return files, o
+}
@@ -1,73 +1,65 @@
 package t3
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"text/template"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/hook"
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/render"
 )
 
-type Config struct {
-	Name     string
-	IsHybrid bool
+// files lists the output paths to render from the template tree.
+var files = []string{
+	"package.json",
+	"tsconfig.json",
+	"tailwind.config.ts",
+	"postcss.config.mjs",
+	"drizzle.config.ts",
+	".env",
+	"next.config.ts",
+	".gitignore",
+	"src/app/globals.css",
+	"src/app/layout.tsx",
+	"src/app/page.tsx",
+	"src/server/db.ts",
+	"src/server/schema.ts",
+	"src/lib/auth.ts",
+	"src/lib/auth-client.ts",
+	"src/app/api/auth/[...all]/route.ts",
+	"src/app/auth/page.tsx",
+	"compose.yml",
 }
 
-func Spawn(rootPath string, name string, isHybrid bool) {
-	fmt.Println("  [T3] Injecting Next.js 16 Architecture...")
-
-	// 1. Create Directories
-	dirs := []string{
-		"src/app",
-		"src/server",
-		"src/lib",
-		"src/app/api/auth/[...all]",
-		"src/app/auth",
-	}
+func Spawn(ctx context.Context, w *render.Writer, rootPath string, o archetype.ProjectOptions) error {
+	report := progress.From(ctx)
+	report.Step("[T3] Injecting Next.js 16 Architecture...")
 
-	for _, dir := range dirs {
-		path := filepath.Join(rootPath, dir)
-		os.MkdirAll(path, 0755)
+	// 1. Render the Files (in case we need {{.Name}})
+	plan, err := Render(o)
+	if err != nil {
+		return fmt.Errorf("t3: %w", err)
 	}
 
 	// 2. Write the Files
-	// We use a helper map to link Filenames -> Template Content
-	files := map[string]string{
-		"package.json":                       PackageJSON,
-		"tsconfig.json":                      TSConfig,
-		"tailwind.config.ts":                 TailwindConfig,
-		"postcss.config.mjs":                 PostCSSConfig,
-		"drizzle.config.ts":                  DrizzleConfig,
-		".env":                               EnvFile,
-		"next.config.ts":                     NextConfig,
-		".gitignore":                         GitIgnore,
-		"src/app/globals.css":                GlobalCSS,
-		"src/app/layout.tsx":                 RootLayout,
-		"src/app/page.tsx":                   MainPage,
-		"src/server/db.ts":                   DBConnection,
-		"src/server/schema.ts":               DBSchema,
-		"src/lib/auth.ts":                    AuthServer,
-		"src/lib/auth-client.ts":             AuthClient,
-		"src/app/api/auth/[...all]/route.ts": AuthRoute,
-		"src/app/auth/page.tsx":              LoginPage,
-		"compose.yml":                        DockerCompose,
-	}
-
-	config := Config{
-		Name:     name,
-		IsHybrid: isHybrid,
+	if err := w.WriteFiles(ctx, rootPath, plan.Files); err != nil {
+		return fmt.Errorf("t3: write %w", err)
 	}
+	return nil
+}
 
-	for filename, content := range files {
-		fullPath := filepath.Join(rootPath, filename)
-
-		// Create the file
-		f, _ := os.Create(fullPath)
-
-		// Parse and Execute Template (in case we need {{.Name}})
-		tmpl, _ := template.New(filename).Parse(content)
-		tmpl.Execute(f, config)
-		f.Close()
-
-		fmt.Printf("    ├── Injected: %s\n", filename)
+// Render produces the files Spawn would write, in memory, along with the
+// hooks that follow: installing the node dependencies with Bun.
+func Render(o archetype.ProjectOptions) (render.Plan, error) {
+	rendered, err := render.Tree(Templates(), files, o)
+	if err != nil {
+		return render.Plan{}, err
 	}
+	return render.Plan{
+		Files: rendered,
+		Commands: []render.Command{
+			{Hook: hook.Install, Name: "bun", Args: []string{"install"}, Network: true},
+		},
+	}, nil
 }
@@ -0,0 +1,104 @@
+// Package templates locates the template tree of an archetype: the files
+// built into the binary, with any user overrides layered on top.
+package templates
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Ext is the suffix every template file carries; the output path is the
+// template path without it.
+const Ext = ".tmpl"
+
+// EnvDir overrides the user template directory when set.
+const EnvDir = "GENESIS_TEMPLATES"
+
+// Dir is the user override directory, ~/.config/genesis/templates by
+// default. It returns "" if no config directory can be determined.
+func Dir() string {
+	if dir := os.Getenv(EnvDir); dir != "" {
+		return dir
+	}
+	config, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(config, "genesis", "templates")
+}
+
+// For returns the template tree of archetype: builtin, with any file under
+// Dir()/archetype replacing the built-in file at the same path.
+func For(archetype string, builtin fs.FS) fs.FS {
+	dir := Dir()
+	if dir == "" {
+		return builtin
+	}
+	override := filepath.Join(dir, archetype)
+	if info, err := os.Stat(override); err != nil || !info.IsDir() {
+		return builtin
+	}
+	return &overlay{top: os.DirFS(override), bottom: builtin}
+}
+
+// Overrides lists the files in fsys that come from the user override
+// directory: replaced shadow a built-in file, added have no built-in
+// counterpart.
+func Overrides(fsys fs.FS) (replaced, added []string) {
+	o, ok := fsys.(*overlay)
+	if !ok {
+		return nil, nil
+	}
+	fs.WalkDir(o.top, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if _, err := fs.Stat(o.bottom, p); err == nil {
+			replaced = append(replaced, p)
+		} else {
+			added = append(added, p)
+		}
+		return nil
+	})
+	return replaced, added
+}
+
+// overlay serves files from top when present there, otherwise from bottom.
+type overlay struct {
+	top, bottom fs.FS
+}
+
+func (o *overlay) Open(name string) (fs.File, error) {
+	if f, err := o.top.Open(name); err == nil {
+		if info, err := f.Stat(); err == nil && !info.IsDir() {
+			return f, nil
+		}
+		f.Close()
+	}
+	return o.bottom.Open(name)
+}
+
+// ReadDir merges the listings of both layers.
+func (o *overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	top, topErr := fs.ReadDir(o.top, name)
+	bottom, bottomErr := fs.ReadDir(o.bottom, name)
+	if topErr != nil && bottomErr != nil {
+		return nil, bottomErr
+	}
+
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for _, e := range top {
+		seen[e.Name()] = true
+		entries = append(entries, e)
+	}
+	for _, e := range bottom {
+		if !seen[e.Name()] {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
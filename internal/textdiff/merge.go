@@ -0,0 +1,109 @@
+package textdiff
+
+import (
+	"strings"
+)
+
+// Labels name the three sides in conflict markers.
+type Labels struct {
+	Ours   string
+	Base   string
+	Theirs string
+}
+
+// Merge3 merges the changes base->ours and base->theirs line by line.
+// Regions changed on only one side, or identically on both, merge cleanly;
+// anything else is written out between diff3-style conflict markers and
+// reported by the boolean result.
+func Merge3(base, ours, theirs []byte, labels Labels) ([]byte, bool) {
+	b, o, t := Lines(base), Lines(ours), Lines(theirs)
+	matchO := matches(len(b), Diff(b, o))
+	matchT := matches(len(b), Diff(b, t))
+
+	var out strings.Builder
+	conflicted := false
+	i, oi, ti := 0, 0, 0
+	for {
+		// Stable line: unchanged on both sides.
+		if i < len(b) && matchO[i] == oi && matchT[i] == ti {
+			out.WriteString(b[i])
+			i, oi, ti = i+1, oi+1, ti+1
+			continue
+		}
+
+		// Find the next base line both sides still agree on.
+		j := i
+		for j < len(b) && (matchO[j] < 0 || matchT[j] < 0) {
+			j++
+		}
+		oEnd, tEnd := len(o), len(t)
+		if j < len(b) {
+			oEnd, tEnd = matchO[j], matchT[j]
+		}
+
+		baseChunk, oursChunk, theirsChunk := b[i:j], o[oi:oEnd], t[ti:tEnd]
+		switch {
+		case equal(oursChunk, baseChunk):
+			writeLines(&out, theirsChunk)
+		case equal(theirsChunk, baseChunk), equal(oursChunk, theirsChunk):
+			writeLines(&out, oursChunk)
+		default:
+			conflicted = true
+			writeMarker(&out, "<<<<<<< "+labels.Ours)
+			writeLines(&out, oursChunk)
+			writeMarker(&out, "||||||| "+labels.Base)
+			writeLines(&out, baseChunk)
+			writeMarker(&out, "=======")
+			writeLines(&out, theirsChunk)
+			writeMarker(&out, ">>>>>>> "+labels.Theirs)
+		}
+
+		if j == len(b) {
+			break
+		}
+		i, oi, ti = j, oEnd, tEnd
+	}
+	return []byte(out.String()), conflicted
+}
+
+// matches maps every base line to its index on the other side, or -1 if
+// the edit script deleted it.
+func matches(n int, edits []Edit) []int {
+	m := make([]int, n)
+	for i := range m {
+		m[i] = -1
+	}
+	for _, e := range edits {
+		if e.Op == Equal {
+			m[e.A] = e.B
+		}
+	}
+	return m
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+// writeMarker starts a marker on a fresh line even if the previous chunk
+// ended without a newline.
+func writeMarker(out *strings.Builder, marker string) {
+	if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		out.WriteString("\n")
+	}
+	out.WriteString(marker + "\n")
+}
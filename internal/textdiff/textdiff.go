@@ -0,0 +1,222 @@
+package textdiff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of a single line edit.
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is one line of an edit script turning a into b. A and B are the
+// zero-based line indices in each side (-1 when the line is absent there).
+type Edit struct {
+	Op   Op
+	Line string
+	A, B int
+}
+
+// Lines splits s into lines, keeping the trailing newline on each.
+func Lines(s []byte) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	var lines []string
+	for len(s) > 0 {
+		i := bytes.IndexByte(s, '\n')
+		if i < 0 {
+			lines = append(lines, string(s))
+			break
+		}
+		lines = append(lines, string(s[:i+1]))
+		s = s[i+1:]
+	}
+	return lines
+}
+
+// Diff returns the shortest edit script from a to b (Myers' algorithm).
+func Diff(a, b []string) []Edit {
+	// Trim the common prefix and suffix; the search is quadratic in what
+	// remains.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var edits []Edit
+	for i := 0; i < pre; i++ {
+		edits = append(edits, Edit{Op: Equal, Line: a[i], A: i, B: i})
+	}
+	for _, e := range myers(a[pre:len(a)-suf], b[pre:len(b)-suf]) {
+		if e.A >= 0 {
+			e.A += pre
+		}
+		if e.B >= 0 {
+			e.B += pre
+		}
+		edits = append(edits, e)
+	}
+	for i := 0; i < suf; i++ {
+		ai, bi := len(a)-suf+i, len(b)-suf+i
+		edits = append(edits, Edit{Op: Equal, Line: a[ai], A: ai, B: bi})
+	}
+	return edits
+}
+
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit == 0 {
+		return nil
+	}
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, off)
+			}
+		}
+	}
+	return nil // unreachable: d == limit always reaches the end
+}
+
+func backtrack(trace [][]int, a, b []string, off int) []Edit {
+	x, y := len(a), len(b)
+	var rev []Edit
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[off+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			rev = append(rev, Edit{Op: Equal, Line: a[x-1], A: x - 1, B: y - 1})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				rev = append(rev, Edit{Op: Insert, Line: b[y-1], A: -1, B: y - 1})
+			} else {
+				rev = append(rev, Edit{Op: Delete, Line: a[x-1], A: x - 1, B: -1})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	edits := make([]Edit, len(rev))
+	for i, e := range rev {
+		edits[len(rev)-1-i] = e
+	}
+	return edits
+}
+
+// context is the number of unchanged lines shown around each hunk.
+const context = 3
+
+// Unified renders a unified diff from a to b, or "" if they are equal.
+func Unified(fromName, toName string, a, b []byte) string {
+	edits := Diff(Lines(a), Lines(b))
+
+	var changed []int
+	for i, e := range edits {
+		if e.Op != Equal {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	for i := 0; i < len(changed); {
+		// Grow the hunk while the next change is within reach of its context.
+		start, end := changed[i], changed[i]
+		for i++; i < len(changed) && changed[i]-end <= 2*context; i++ {
+			end = changed[i]
+		}
+		start = max(start-context, 0)
+		end = min(end+context, len(edits)-1)
+		writeHunk(&out, edits[start:end+1])
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, hunk []Edit) {
+	aStart, bStart, aLen, bLen := -1, -1, 0, 0
+	for _, e := range hunk {
+		if e.Op != Insert {
+			if aStart < 0 {
+				aStart = e.A
+			}
+			aLen++
+		}
+		if e.Op != Delete {
+			if bStart < 0 {
+				bStart = e.B
+			}
+			bLen++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+
+	for _, e := range hunk {
+		prefix := " "
+		switch e.Op {
+		case Delete:
+			prefix = "-"
+		case Insert:
+			prefix = "+"
+		}
+		out.WriteString(prefix + e.Line)
+		if !strings.HasSuffix(e.Line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, length int) string {
+	// Unified diff lines are one-based; an empty range names the line before it.
+	if length == 0 {
+		return fmt.Sprintf("%d,0", max(start, 0))
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,114 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDiffRoundTrip checks that every edit script rebuilds both sides.
+func TestDiffRoundTrip(t *testing.T) {
+	cases := []struct{ a, b string }{
+		{"", ""},
+		{"", "a\nb\n"},
+		{"a\nb\n", ""},
+		{"a\nb\nc\n", "a\nc\n"},
+		{"a\nb\nc\n", "a\nB\nc\nd\n"},
+		{"x\na\nb\nc\ny\n", "a\nb\nz\nc\n"},
+		{"same\n", "same\n"},
+	}
+
+	for _, tc := range cases {
+		var gotA, gotB strings.Builder
+		for _, e := range Diff(Lines([]byte(tc.a)), Lines([]byte(tc.b))) {
+			if e.Op != Insert {
+				gotA.WriteString(e.Line)
+			}
+			if e.Op != Delete {
+				gotB.WriteString(e.Line)
+			}
+		}
+		if gotA.String() != tc.a || gotB.String() != tc.b {
+			t.Errorf("Diff(%q, %q) rebuilt (%q, %q)", tc.a, tc.b, gotA.String(), gotB.String())
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\n"
+	b := "one\ntwo\nthree\nFOUR\nfive\nsix\nseven\neight\nnine\nten"
+
+	want := `--- a
++++ b
+@@ -1,9 +1,10 @@
+ one
+ two
+ three
+-four
++FOUR
+ five
+ six
+ seven
+ eight
+ nine
++ten
+\ No newline at end of file
+`
+	if got := Unified("a", "b", []byte(a), []byte(b)); got != want {
+		t.Errorf("Unified mismatch:\n%s", got)
+	}
+
+	if got := Unified("a", "b", []byte(a), []byte(a)); got != "" {
+		t.Errorf("Unified of equal inputs = %q, want empty", got)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	labels := Labels{Ours: "yours", Base: "base", Theirs: "genesis"}
+	base := "a\nb\nc\nd\ne\n"
+
+	cases := []struct {
+		name         string
+		ours, theirs string
+		want         string
+		conflict     bool
+	}{
+		{"only ours", "a\nB\nc\nd\ne\n", base, "a\nB\nc\nd\ne\n", false},
+		{"only theirs", base, "a\nb\nc\nD\ne\n", "a\nb\nc\nD\ne\n", false},
+		{"both, apart", "A\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n", "A\nb\nc\nd\nE\n", false},
+		{"both, same", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", false},
+		{"insert at end", base, base + "f\n", base + "f\n", false},
+		{
+			"collision", "a\nmine\nc\nd\ne\n", "a\ntheirs\nc\nd\ne\n",
+			"a\n<<<<<<< yours\nmine\n||||||| base\nb\n=======\ntheirs\n>>>>>>> genesis\nc\nd\ne\n", true,
+		},
+		{
+			"deleted against edited", "a\nc\nd\ne\n", "a\nB\nc\nd\ne\n",
+			"a\n<<<<<<< yours\n||||||| base\nb\n=======\nB\n>>>>>>> genesis\nc\nd\ne\n", true,
+		},
+		{
+			"both append", base + "mine\n", base + "theirs\n",
+			base + "<<<<<<< yours\nmine\n||||||| base\n=======\ntheirs\n>>>>>>> genesis\n", true,
+		},
+	}
+
+	for _, tc := range cases {
+		got, conflict := Merge3([]byte(base), []byte(tc.ours), []byte(tc.theirs), labels)
+		if string(got) != tc.want || conflict != tc.conflict {
+			t.Errorf("%s: Merge3 = (%q, %v), want (%q, %v)", tc.name, got, conflict, tc.want, tc.conflict)
+		}
+	}
+}
+
+// TestMerge3NoBase covers files with no recorded base, where any
+// difference between the sides is a conflict.
+func TestMerge3NoBase(t *testing.T) {
+	labels := Labels{Ours: "yours", Base: "base", Theirs: "genesis"}
+	got, conflict := Merge3(nil, []byte("x\n"), []byte("y\n"), labels)
+	want := "<<<<<<< yours\nx\n||||||| base\n=======\ny\n>>>>>>> genesis\n"
+	if string(got) != want || !conflict {
+		t.Errorf("Merge3 = (%q, %v), want (%q, true)", got, conflict, want)
+	}
+	if got, conflict := Merge3(nil, []byte("x\n"), []byte("x\n"), labels); string(got) != "x\n" || conflict {
+		t.Errorf("Merge3 of equal sides = (%q, %v), want (\"x\\n\", false)", got, conflict)
+	}
+}
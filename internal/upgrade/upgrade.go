@@ -0,0 +1,168 @@
+package upgrade
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/textdiff"
+)
+
+// Action is what an upgrade does to one file.
+type Action string
+
+const (
+	Unchanged Action = "unchanged" // nothing to do
+	Updated   Action = "updated"   // untouched locally; replaced by the new template
+	Merged    Action = "merged"    // changed on both sides; merged cleanly
+	Conflict  Action = "conflict"  // changed on both sides; conflict markers written
+	Rejected  Action = "rejected"  // could not be applied; a .rej file holds the template change
+	Added     Action = "added"     // new in the templates
+	Removed   Action = "removed"   // dropped from the templates and untouched locally
+	Kept      Action = "kept"      // dropped from the templates but edited locally
+)
+
+// RejectSuffix is appended to a file's path for its .rej companion.
+const RejectSuffix = ".rej"
+
+// Change is the outcome for one file. Content is what to write to Path
+// (nil when nothing is written); Reject is written to Path+RejectSuffix.
+type Change struct {
+	Path    string
+	Action  Action
+	Content []byte
+	Reject  []byte
+}
+
+// Input is everything an upgrade needs to decide per-file outcomes.
+type Input struct {
+	Root     string             // project on disk ("yours")
+	Manifest *manifest.Manifest // what was generated last time
+	Base     map[string][]byte  // last generated content, where known
+	Next     render.Plan        // what today's templates produce
+	Labels   textdiff.Labels    // names for conflict markers
+	Rej      bool               // write .rej files instead of conflict markers
+}
+
+// Plan decides, file by file, how to carry the project forward to Next.
+// It reads from disk but writes nothing.
+func Plan(in Input) ([]Change, error) {
+	next := map[string][]byte{}
+	paths := map[string]bool{}
+	for _, f := range in.Next.Files {
+		if manifest.IsProvenance(f.Path) {
+			continue
+		}
+		next[f.Path] = f.Content
+		paths[f.Path] = true
+	}
+	for p := range in.Manifest.Files {
+		if !manifest.IsProvenance(p) {
+			paths[p] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, p := range sorted {
+		ours, err := os.ReadFile(filepath.Join(in.Root, filepath.FromSlash(p)))
+		onDisk := err == nil
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		changes = append(changes, in.decide(p, ours, onDisk, next))
+	}
+	return changes, nil
+}
+
+func (in Input) decide(p string, ours []byte, onDisk bool, next map[string][]byte) Change {
+	theirs, inNext := next[p]
+	recorded, wasGenerated := in.Manifest.Files[p]
+	base, hasBase := in.Base[p]
+
+	switch {
+	case !inNext:
+		// Dropped from the templates.
+		if !onDisk {
+			return Change{Path: p, Action: Unchanged}
+		}
+		if manifest.Hash(ours) == recorded {
+			return Change{Path: p, Action: Removed}
+		}
+		return Change{Path: p, Action: Kept}
+
+	case !onDisk:
+		if !wasGenerated {
+			return Change{Path: p, Action: Added, Content: theirs}
+		}
+		// Deleted locally: respect that unless the template moved on.
+		if manifest.Hash(theirs) == recorded {
+			return Change{Path: p, Action: Unchanged}
+		}
+		return Change{Path: p, Action: Rejected, Reject: in.reject(p, base, theirs)}
+
+	case bytes.Equal(ours, theirs):
+		return Change{Path: p, Action: Unchanged}
+
+	case wasGenerated && manifest.Hash(ours) == recorded:
+		return Change{Path: p, Action: Updated, Content: theirs}
+
+	case wasGenerated && manifest.Hash(theirs) == recorded:
+		// The template did not change; the local edits stand.
+		return Change{Path: p, Action: Unchanged}
+	}
+
+	if !hasBase {
+		base = nil
+	}
+	merged, conflicted := textdiff.Merge3(base, ours, theirs, in.Labels)
+	switch {
+	case !conflicted:
+		return Change{Path: p, Action: Merged, Content: merged}
+	case in.Rej:
+		return Change{Path: p, Action: Rejected, Reject: in.reject(p, base, theirs)}
+	default:
+		return Change{Path: p, Action: Conflict, Content: merged}
+	}
+}
+
+// reject renders the template change that could not be applied.
+func (in Input) reject(p string, base, theirs []byte) []byte {
+	return []byte(textdiff.Unified(p+" ("+in.Labels.Base+")", p+" ("+in.Labels.Theirs+")", base, theirs))
+}
+
+// RecoverBase returns the merge base for each file: the stored baseline
+// if the project has one, otherwise whatever part of Next still hashes to
+// what the manifest recorded.
+func RecoverBase(root string, m *manifest.Manifest, next render.Plan) (map[string][]byte, error) {
+	base, err := manifest.LoadBaseline(root)
+	if err == nil {
+		// Only trust snapshot entries that match the manifest.
+		for p, content := range base {
+			if m.Files[p] != manifest.Hash(content) {
+				delete(base, p)
+			}
+		}
+		return base, nil
+	}
+	if !errors.Is(err, manifest.ErrNoBaseline) {
+		return nil, err
+	}
+
+	base = map[string][]byte{}
+	for _, f := range next.Files {
+		if m.Files[f.Path] == manifest.Hash(f.Content) {
+			base[f.Path] = f.Content
+		}
+	}
+	return base, nil
+}
@@ -0,0 +1,117 @@
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/textdiff"
+)
+
+// TestPlan walks the decision table: one file per row, each with what was
+// generated last time, what is on disk and what the templates give now.
+func TestPlan(t *testing.T) {
+	const (
+		base   = "a\nb\nc\nd\ne\n"
+		local  = "A\nb\nc\nd\ne\n" // edited at the top
+		tmpl   = "a\nb\nc\nd\nE\n" // edited at the bottom
+		clash  = "a\nb\nc\nd\nX\n" // edited where tmpl is
+		merged = "A\nb\nc\nd\nE\n"
+	)
+	absent := "\x00" // not on disk, or not in the templates
+
+	cases := []struct {
+		name             string
+		base, ours, next string // base "" means never generated
+		action           Action
+		content          string // what is written contains this; "" means nothing
+		reject           bool
+	}{
+		{"unchanged", base, base, base, Unchanged, "", false},
+		{"template moved, untouched locally", base, base, tmpl, Updated, tmpl, false},
+		{"user-modified, template still", base, local, base, Unchanged, "", false},
+		{"both modified, apart", base, local, tmpl, Merged, merged, false},
+		{"both modified, same place", base, clash, tmpl, Conflict, "<<<<<<< yours", false},
+		{"both made the same change", base, tmpl, tmpl, Unchanged, "", false},
+		{"deleted locally, template still", base, absent, base, Unchanged, "", false},
+		{"deleted locally, template moved", base, absent, tmpl, Rejected, "", true},
+		{"dropped from templates, untouched", base, base, absent, Removed, "", false},
+		{"dropped from templates, edited", base, local, absent, Kept, "", false},
+		{"dropped from templates, deleted", base, absent, absent, Unchanged, "", false},
+		{"new in the templates", "", absent, tmpl, Added, tmpl, false},
+	}
+
+	root := t.TempDir()
+	m := &manifest.Manifest{Files: map[string]string{}}
+	baseFiles := map[string][]byte{}
+	var next render.Plan
+	for i, tc := range cases {
+		p := fmt.Sprintf("f%02d", i)
+		if tc.base != "" {
+			m.Files[p] = manifest.Hash([]byte(tc.base))
+			baseFiles[p] = []byte(tc.base)
+		}
+		if tc.ours != absent {
+			if err := os.WriteFile(filepath.Join(root, p), []byte(tc.ours), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if tc.next != absent {
+			next.Files = append(next.Files, render.File{Path: p, Content: []byte(tc.next), Mode: 0644})
+		}
+	}
+
+	changes, err := Plan(Input{
+		Root:     root,
+		Manifest: m,
+		Base:     baseFiles,
+		Next:     next,
+		Labels:   textdiff.Labels{Ours: "yours", Base: "base", Theirs: "genesis"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != len(cases) {
+		t.Fatalf("got %d changes, want %d", len(changes), len(cases))
+	}
+	for i, tc := range cases {
+		c := changes[i]
+		if c.Action != tc.action {
+			t.Errorf("%s: action %s, want %s", tc.name, c.Action, tc.action)
+		}
+		if !strings.Contains(string(c.Content), tc.content) || (tc.content == "") != (c.Content == nil) {
+			t.Errorf("%s: content %q, want %q", tc.name, c.Content, tc.content)
+		}
+		if (c.Reject != nil) != tc.reject {
+			t.Errorf("%s: reject %q, want one: %v", tc.name, c.Reject, tc.reject)
+		}
+	}
+}
+
+// TestPlanRej checks that -rej leaves a colliding file alone and writes
+// the template change beside it instead.
+func TestPlanRej(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "f"), []byte("mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := Plan(Input{
+		Root:     root,
+		Manifest: &manifest.Manifest{Files: map[string]string{"f": manifest.Hash([]byte("base\n"))}},
+		Base:     map[string][]byte{"f": []byte("base\n")},
+		Next:     render.Plan{Files: []render.File{{Path: "f", Content: []byte("theirs\n")}}},
+		Labels:   textdiff.Labels{Base: "base", Theirs: "genesis"},
+		Rej:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := changes[0]
+	if c.Action != Rejected || c.Content != nil || !strings.Contains(string(c.Reject), "+theirs") {
+		t.Errorf("got %s content %q reject %q, want a reject and the file left alone", c.Action, c.Content, c.Reject)
+	}
+}
@@ -0,0 +1,6 @@
+package version
+
+// Version is the Genesis release stamped into every generated manifest.
+// Release builds may override it with
+// -ldflags "-X github.com/holodanger/genesis/internal/version.Version=<v>".
+var Version = "1.1.0"
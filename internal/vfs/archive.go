@@ -0,0 +1,130 @@
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"path"
+	"sync"
+	"time"
+)
+
+// Archive is an FS that streams everything written to it into a tar.gz or
+// zip, keeping file modes. Files cannot be overwritten once written; Close
+// must be called to finish the archive.
+type Archive struct {
+	mu      sync.Mutex
+	gz      *gzip.Writer
+	tw      *tar.Writer
+	zw      *zip.Writer
+	dirs    map[string]bool
+	files   map[string]bool
+	modTime time.Time
+}
+
+// NewTarGz returns an Archive writing a gzip-compressed tar to w.
+func NewTarGz(w io.Writer) *Archive {
+	gz := gzip.NewWriter(w)
+	return newArchive(&Archive{gz: gz, tw: tar.NewWriter(gz)})
+}
+
+// NewZip returns an Archive writing a zip to w.
+func NewZip(w io.Writer) *Archive {
+	return newArchive(&Archive{zw: zip.NewWriter(w)})
+}
+
+func newArchive(a *Archive) *Archive {
+	a.dirs = map[string]bool{}
+	a.files = map[string]bool{}
+	a.modTime = time.Now().Truncate(time.Second)
+	return a
+}
+
+func (a *Archive) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.mkdirAll(name, perm)
+}
+
+func (a *Archive) mkdirAll(name string, perm fs.FileMode) error {
+	if name == "." || a.dirs[name] {
+		return nil
+	}
+	if a.files[name] {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if err := a.mkdirAll(path.Dir(name), perm); err != nil {
+		return err
+	}
+	a.dirs[name] = true
+	if a.tw != nil {
+		return a.tw.WriteHeader(&tar.Header{
+			Name:     name + "/",
+			Mode:     int64(perm.Perm()),
+			Typeflag: tar.TypeDir,
+			ModTime:  a.modTime,
+			Format:   tar.FormatPAX,
+		})
+	}
+	h := &zip.FileHeader{Name: name + "/", Modified: a.modTime}
+	h.SetMode(fs.ModeDir | perm.Perm())
+	_, err := a.zw.CreateHeader(h)
+	return err
+}
+
+func (a *Archive) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.files[name] || a.dirs[name] {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+	if err := a.mkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+	a.files[name] = true
+
+	if a.tw != nil {
+		hdr := &tar.Header{
+			Name:     name,
+			Mode:     int64(perm.Perm()),
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+			ModTime:  a.modTime,
+			Format:   tar.FormatPAX,
+		}
+		if err := a.tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := a.tw.Write(data)
+		return err
+	}
+	h := &zip.FileHeader{Name: name, Method: zip.Deflate, Modified: a.modTime}
+	h.SetMode(perm.Perm())
+	fw, err := a.zw.CreateHeader(h)
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (a *Archive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.tw != nil {
+		if err := a.tw.Close(); err != nil {
+			return err
+		}
+		return a.gz.Close()
+	}
+	return a.zw.Close()
+}
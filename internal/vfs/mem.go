@@ -0,0 +1,93 @@
+package vfs
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"sync"
+	"testing/fstest"
+	"time"
+)
+
+// Mem is an in-memory FS. It is also an fs.FS, so what was written can be
+// read back, walked and verified without touching the disk.
+type Mem struct {
+	mu    sync.Mutex
+	files fstest.MapFS
+}
+
+// NewMem returns an empty in-memory FS.
+func NewMem() *Mem {
+	return &Mem{files: fstest.MapFS{}}
+}
+
+func (m *Mem) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for d := name; d != "."; d = path.Dir(d) {
+		if f, ok := m.files[d]; ok {
+			if !f.Mode.IsDir() {
+				return &fs.PathError{Op: "mkdir", Path: d, Err: fs.ErrExist}
+			}
+			continue
+		}
+		m.files[d] = &fstest.MapFile{Mode: fs.ModeDir | perm.Perm()}
+	}
+	return nil
+}
+
+func (m *Mem) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	if dir := path.Dir(name); dir != "." {
+		if err := m.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f, ok := m.files[name]; ok && f.Mode.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+	m.files[name] = &fstest.MapFile{
+		Data:    append([]byte(nil), data...),
+		Mode:    perm.Perm(),
+		ModTime: time.Now(),
+	}
+	return nil
+}
+
+// Open implements fs.FS over what has been written so far.
+func (m *Mem) Open(name string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot().Open(name)
+}
+
+// Paths lists the regular files written, sorted.
+func (m *Mem) Paths() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var paths []string
+	for name, f := range m.files {
+		if !f.Mode.IsDir() {
+			paths = append(paths, name)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// snapshot copies the index so an open file or directory listing is not
+// disturbed by later writes.
+func (m *Mem) snapshot() fstest.MapFS {
+	c := make(fstest.MapFS, len(m.files))
+	for k, v := range m.files {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,96 @@
+// Package vfs is the filesystem generation writes through. Builders see
+// only FS, so the same generation can land on disk, in memory or in an
+// archive.
+package vfs
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// FS is a write target. Names are slash-separated and relative to the
+// target's root (see fs.ValidPath); parent directories are created as
+// needed.
+type FS interface {
+	MkdirAll(name string, perm fs.FileMode) error
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// OS is the directory tree rooted at Dir on disk.
+type OS struct {
+	Dir string
+}
+
+func (o OS) MkdirAll(name string, perm fs.FileMode) error {
+	p, err := o.path(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(p, perm)
+}
+
+func (o OS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p, err := o.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, perm)
+}
+
+func (o OS) path(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(o.Dir, filepath.FromSlash(name)), nil
+}
+
+// Sub returns an FS that writes into dir inside fsys.
+func Sub(fsys FS, dir string) FS {
+	return sub{fsys: fsys, dir: dir}
+}
+
+type sub struct {
+	fsys FS
+	dir  string
+}
+
+func (s sub) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return s.fsys.MkdirAll(path.Join(s.dir, name), perm)
+}
+
+func (s sub) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	return s.fsys.WriteFile(path.Join(s.dir, name), data, perm)
+}
+
+// Copy writes every file in src to dst, keeping permissions, and returns
+// the number of bytes copied.
+func Copy(dst FS, src fs.FS) (int, error) {
+	total := 0
+	err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := fs.ReadFile(src, name)
+		if err != nil {
+			return err
+		}
+		total += len(data)
+		return dst.WriteFile(name, data, info.Mode().Perm())
+	})
+	return total, err
+}
@@ -0,0 +1,111 @@
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// write puts the same small tree into any FS.
+func write(t *testing.T, fsys FS) {
+	t.Helper()
+	if err := fsys.WriteFile("a/b/main.go", []byte("package b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("run.sh", []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("../escape", nil, 0644); err == nil {
+		t.Error("writing outside the root succeeded")
+	}
+}
+
+func TestMem(t *testing.T) {
+	m := NewMem()
+	write(t, m)
+
+	if got := m.Paths(); len(got) != 2 || got[0] != "a/b/main.go" || got[1] != "run.sh" {
+		t.Errorf("Paths() = %v", got)
+	}
+	data, err := fs.ReadFile(m, "a/b/main.go")
+	if err != nil || string(data) != "package b\n" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+	if info, err := fs.Stat(m, "run.sh"); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh mode = %v, %v", info.Mode(), err)
+	}
+	if err := m.WriteFile("a/b", nil, 0644); err == nil {
+		t.Error("a file replaced a directory")
+	}
+}
+
+func TestOS(t *testing.T) {
+	dir := t.TempDir()
+	write(t, OS{Dir: dir})
+
+	info, err := os.Stat(filepath.Join(dir, "run.sh"))
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh mode = %v, %v", info.Mode(), err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a", "b", "main.go")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	modes := func(t *testing.T, got map[string]fs.FileMode) {
+		t.Helper()
+		if got["p/run.sh"] != 0755 || got["p/a/b/main.go"] != 0644 || !got["p/a/"].IsDir() {
+			t.Errorf("archive entries = %v", got)
+		}
+	}
+
+	t.Run("tar.gz", func(t *testing.T) {
+		var buf bytes.Buffer
+		a := NewTarGz(&buf)
+		write(t, Sub(a, "p"))
+		if err := a.Close(); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]fs.FileMode{}
+		tr := tar.NewReader(zr)
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			got[h.Name] = h.FileInfo().Mode() & (fs.ModeDir | fs.ModePerm)
+		}
+		modes(t, got)
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		var buf bytes.Buffer
+		a := NewZip(&buf)
+		write(t, Sub(a, "p"))
+		if err := a.Close(); err != nil {
+			t.Fatal(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]fs.FileMode{}
+		for _, f := range zr.File {
+			got[f.Name] = f.Mode() & (fs.ModeDir | fs.ModePerm)
+		}
+		modes(t, got)
+	})
+}
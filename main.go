@@ -1,98 +1,131 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strings"
 
-	// IMPORT MODULES
-	"github.com/holodanger/genesis/internal/goservice"
-	"github.com/holodanger/genesis/internal/hybrid"
-	"github.com/holodanger/genesis/internal/t3"
+	"github.com/holodanger/genesis/internal/version"
 )
 
-func main() {
-	// 1. TACTICAL INPUT
-	projectName := flag.String("name", "", "Project Name")
-	projectType := flag.String("type", "t3", "Archetype: t3 (Frontend Shield) | go (Backend Spear) | hybrid (Twin Architecture)")
-	aiEnabled := flag.Bool("ai", false, "Enable AI Features (OpenAI)")
-	flag.Parse()
-
-	if *projectName == "" {
-		fmt.Println("⚠️  [USAGE] genesis -name <project_name> -type <t3|go|hybrid> -ai=<true|false>")
-		os.Exit(1)
-	}
+// command is a single entry in the Genesis command tree.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	// 2. ROOT ESTABLISHMENT
-	// We establish the root path here, but the specific builders
-	// handle their internal file structures.
-	currentDir, _ := os.Getwd()
-	rootPath := filepath.Join(currentDir, *projectName)
+// commands is the ordered command table. Order matters: it is the order
+// printed by the top-level usage.
+var commands = []command{
+	{name: "new", summary: "Spawn a new project from an archetype", run: runNew},
+	{name: "upgrade", summary: "Re-render an existing project against newer templates", run: runUpgrade},
+	{name: "diff", summary: "Show how a project has drifted from the template baseline", run: runDiff},
+	{name: "templates", summary: "Lint the built-in, override and pack templates", run: runTemplates},
+	{name: "cache", summary: "Prepare the dependency cache offline spawns install from", run: runCache},
+	{name: "doctor", summary: "Check the local toolchain (go, bun, docker, git, sqlc, gofmt)", run: runDoctor},
+	{name: "list", summary: "List the available archetypes", run: runList},
+}
 
-	// Create root if it doesn't exist (Idempotency)
-	if err := os.MkdirAll(rootPath, 0755); err != nil {
-		fmt.Printf("❌ [ERROR] Failed to secure territory: %v\n", err)
-		os.Exit(1)
-	}
+// Exit codes.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
 
-	fmt.Printf("\n⚔️  [GENESIS] Spawning Archetype: %s | Node: %s | AI: %v\n", *projectType, *projectName, *aiEnabled)
+// usageError marks errors caused by bad invocation rather than a failed run.
+type usageError struct{ msg string }
 
-	// 3. STRATEGY EXECUTION
-	switch *projectType {
-	case "t3":
-		deployT3(rootPath, *projectName)
-	case "go":
-		deployGoService(*projectName, *aiEnabled)
-	case "hybrid":
-		hybrid.Spawn(rootPath, *projectName, *aiEnabled)
-	default:
-		fmt.Printf("❌ [ERROR] Unknown archetype: '%s'. Options: t3, go, hybrid\n", *projectType)
-		os.Exit(1)
-	}
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
 }
 
-// --- TACTICAL SUBROUTINES ---
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-func deployT3(root, name string) {
-	// 1. Build Files
-	t3.Spawn(root, name, false)
+func run(args []string) int {
+	// Legacy invocation: `genesis -name X -type t3` is an alias for `genesis new`.
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") && !isHelpFlag(args[0]) {
+		return exitCode(runNew(args))
+	}
 
-	// 2. Install Deps (Bun)
-	fmt.Println("📦 [BUN] Installing Dependencies... (Hold Fast)")
-	cmd := exec.Command("bun", "install")
-	cmd.Dir = root
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Bare `genesis` in a terminal is the wizard; anywhere else, the usage.
+	if len(args) == 0 && isTerminal(os.Stdin) {
+		return exitCode(runNew(nil))
+	}
 
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("⚠️  [WARN] Dependency install failed: %v\n", err)
+	if len(args) == 0 || isHelpFlag(args[0]) || args[0] == "help" {
+		if len(args) > 1 && args[0] == "help" {
+			if cmd, ok := lookup(args[1]); ok {
+				return exitCode(cmd.run([]string{"-h"}))
+			}
+		}
+		printUsage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
 	}
 
-	// 3. Debrief
-	printDebrief(name, "bun dev")
+	cmd, ok := lookup(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "❌ [ERROR] Unknown command: '%s'\n\n", args[0])
+		printUsage()
+		return exitUsage
+	}
+	return exitCode(cmd.run(args[1:]))
 }
 
-func deployGoService(name string, withAI bool) {
-	// 1. Initialize Builder
-	// The Go builder handles its own file generation and 'go mod tidy'
-	builder := goservice.NewBuilder(name, withAI)
-
-	// 2. Execute
-	if err := builder.Build(); err != nil {
-		fmt.Printf("❌ [FATAL] Forge failed: %v\n", err)
-		os.Exit(1)
+func lookup(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
 	}
+	return command{}, false
+}
 
-	// 3. Debrief
-	printDebrief(name, "make run")
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
 }
 
-func printDebrief(name, runCmd string) {
-	fmt.Printf("\n✅ [SUCCESS] Node '%s' is operational.\n", name)
-	fmt.Println("   -------------------------------------")
-	fmt.Printf("   cd %s\n", name)
-	fmt.Printf("   %s\n", runCmd)
-	fmt.Println("   -------------------------------------")
+// exitCode maps a command result onto the process exit code and reports
+// the error, if any.
+func exitCode(err error) int {
+	var uerr *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errFlagParse):
+		// The flag package already printed the problem and the usage.
+		return exitUsage
+	case errors.As(err, &uerr):
+		fmt.Fprintf(os.Stderr, "⚠️  [USAGE] %v\n", err)
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "❌ [FATAL] %v\n", err)
+		return exitFailure
+	}
+}
+
+func printUsage() {
+	w := os.Stderr
+	fmt.Fprintf(w, "Genesis Engine v%s - Production-Ready Scaffolding CLI\n", version.Version)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  genesis <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'genesis <command> -h' for command-specific help.")
+	fmt.Fprintln(w, "'genesis -name <project> -type <t3|go|hybrid>' is an alias for 'genesis new'.")
 }
@@ -2,7 +2,14 @@ package main
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/templates"
+	"github.com/holodanger/genesis/internal/upgrade"
 )
 
 // TestSanity ensures the environment is capable of running the genesis logic.
@@ -18,3 +25,125 @@ func TestSanity(t *testing.T) {
 	// If main.go fails to compile, this test won't even run.
 	t.Log("Genesis Engine compiled successfully.")
 }
+
+// TestCommandExitCodes pins the exit codes of the command dispatcher.
+func TestCommandExitCodes(t *testing.T) {
+	cases := []struct {
+		args []string
+		want int
+	}{
+		{nil, exitUsage},
+		{[]string{"help"}, exitOK},
+		{[]string{"list"}, exitOK},
+		{[]string{"new", "-h"}, exitOK},
+		{[]string{"new"}, exitUsage},
+		{[]string{"new", "-no-input"}, exitUsage},
+		{[]string{"new", "-name", "x", "-type", "rust"}, exitUsage},
+		{[]string{"-name", "x", "-type", "rust"}, exitUsage},
+		{[]string{"new", "-name", "x", "-out", "x.rar"}, exitUsage},
+		{[]string{"new", "-name", "x", "-opt", "web-port=-1"}, exitUsage},
+		{[]string{"new", "-name", "x", "-type", "go", "-opt", "api-port=70000"}, exitUsage},
+		{[]string{"new", "-name", "x", "-out", "x.zip", "-dry-run"}, exitUsage},
+		{[]string{"templates"}, exitUsage},
+		{[]string{"templates", "lint", "-h"}, exitOK},
+		{[]string{"cache"}, exitUsage},
+		{[]string{"cache", "prepare", "-h"}, exitOK},
+		{[]string{"conquer"}, exitUsage},
+	}
+
+	for _, tc := range cases {
+		if got := run(tc.args); got != tc.want {
+			t.Errorf("run(%q) = %d, want %d", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestWizard(t *testing.T) {
+	in := strings.NewReader("../x\nwiz\nhybrid\n7\nopenai\nfacebook\n1,github\nexample.com/wiz\n\n\n4000\n\n")
+	var out strings.Builder
+	s, err := wizard(in, &out)
+	if err != nil {
+		t.Fatalf("wizard: %v\n%s", err, out.String())
+	}
+	if s.Name != "wiz" || s.Archetype != "hybrid" || s.AI.Provider != "openai" || s.Module != "example.com/wiz" || s.Ports.API != 4000 {
+		t.Fatalf("got %+v", s)
+	}
+	if strings.Join(s.Auth.Providers, ",") != "email,github" || !strings.Contains(out.String(), "-opt auth=email,github") {
+		t.Errorf("auth = %v, want email and github in the spec and the equivalent command:\n%s", s.Auth.Providers, out.String())
+	}
+	if !strings.Contains(out.String(), "must start with a letter") {
+		t.Errorf("bad name was not rejected:\n%s", out.String())
+	}
+
+	_, err = wizard(strings.NewReader("wiz\nt3\n"), &out)
+	if err == nil || !strings.Contains(err.Error(), "input closed") {
+		t.Errorf("closed input: got %v", err)
+	}
+}
+
+// TestBuiltinTemplatesLintClean keeps the shipped templates free of parse
+// errors and references to fields their data model lacks.
+func TestBuiltinTemplatesLintClean(t *testing.T) {
+	t.Setenv(templates.EnvDir, t.TempDir())
+	if err := runTemplatesLint(nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestExportArchive checks that -out writes only the archive: no project
+// directory and no stage are left in the working directory.
+func TestExportArchive(t *testing.T) {
+	t.Setenv(templates.EnvDir, t.TempDir())
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := runNew([]string{"-name", "exported", "-type", "go", "-out", "exported.tgz", "-no-input"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "exported.tgz" {
+		t.Errorf("working directory holds %v, want only the archive", entries)
+	}
+}
+
+// TestApplyUpgradeKeepsMode checks that upgraded files take their mode
+// from the new plan instead of losing the executable bit.
+func TestApplyUpgradeKeepsMode(t *testing.T) {
+	t.Setenv(templates.EnvDir, t.TempDir())
+	root := t.TempDir()
+	arch, _ := archetype.Lookup("go")
+	values, err := archetype.Resolve(arch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := archetype.Project{Name: "modes", Root: root, Options: values}
+	next := render.Plan{Files: []render.File{
+		{Path: "run.sh", Content: []byte("#!/bin/sh\n"), Mode: 0755},
+		{Path: "README.md", Content: []byte("# modes\n"), Mode: 0644},
+	}}
+	changes := []upgrade.Change{
+		{Path: "run.sh", Action: upgrade.Updated, Content: []byte("#!/bin/sh\n")},
+		{Path: "README.md", Action: upgrade.Updated, Content: []byte("# modes\n")},
+	}
+	if err := applyUpgrade(root, arch, project, next, changes); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]os.FileMode{"run.sh": 0755, "README.md": 0644} {
+		info, err := os.Stat(filepath.Join(root, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("%s: mode %v, want %v", name, got, want)
+		}
+	}
+}
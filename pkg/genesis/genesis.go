@@ -0,0 +1,349 @@
+// Package genesis generates projects from the Genesis archetypes and from
+// local template packs. It is the engine behind the genesis command,
+// exposed for tools that drive generation themselves: a portal, a test
+// suite, another CLI.
+//
+// Generation never touches the working directory and never runs external
+// commands; the commands a project needs afterwards are listed in the
+// Report.
+package genesis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/hook"
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/pack"
+	"github.com/holodanger/genesis/internal/progress"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/stage"
+	"github.com/holodanger/genesis/internal/version"
+	"github.com/holodanger/genesis/internal/vfs"
+
+	// The built-in archetypes register themselves.
+	_ "github.com/holodanger/genesis/internal/goservice"
+	_ "github.com/holodanger/genesis/internal/hybrid"
+	_ "github.com/holodanger/genesis/internal/t3"
+)
+
+// DefaultArchetype is used when Options names neither an archetype nor a
+// template pack.
+const DefaultArchetype = "t3"
+
+// Options describes the project to generate.
+type Options struct {
+	Name      string            // project name; see ValidateName
+	Archetype string            // built-in archetype, see Archetypes
+	Template  string            // directory of a template pack, instead of Archetype
+	Values    map[string]string // archetype options by name, as strings ("true", "8080")
+	Progress  func(Event)       // receives progress as it happens; may be nil
+}
+
+// Report describes a finished generation.
+type Report struct {
+	Archetype string
+	Name      string
+	Files     []File    // every file written, sorted by path
+	Commands  []Command // what the project needs run before first use, in order
+}
+
+// File is one generated file.
+type File struct {
+	Path string // slash-separated, relative to the project root
+	Size int
+	Mode fs.FileMode
+}
+
+// Command is a post-generation hook the project expects to be run.
+type Command struct {
+	Hook    string // pipeline stage: "install", "tidy", "format", "git" or "codegen"
+	Dir     string // slash-separated, relative to the project root ("" is the root)
+	Name    string
+	Args    []string
+	Network bool          // needs the network
+	Timeout time.Duration // how long the genesis command lets it run before stopping it
+}
+
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Event is a progress report from a running generation.
+type Event struct {
+	Kind EventKind
+	Node string // sub-project the event belongs to ("web", "api"); "" for the project itself
+	Path string
+	Msg  string
+}
+
+// EventKind says what an Event reports.
+type EventKind int
+
+const (
+	EventStep    EventKind = iota // a phase begins; Msg describes it
+	EventFile                     // a file was written; Path is relative to Node
+	EventCommand                  // an external command starts
+	EventOutput                   // a line of command output
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventStep:
+		return "step"
+	case EventFile:
+		return "file"
+	case EventCommand:
+		return "command"
+	case EventOutput:
+		return "output"
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// eventKinds maps the engine's progress kinds onto the public ones.
+var eventKinds = map[progress.Kind]EventKind{
+	progress.Step:    EventStep,
+	progress.File:    EventFile,
+	progress.Command: EventCommand,
+	progress.Output:  EventOutput,
+}
+
+// reportTo adapts f to the engine's progress callbacks.
+func reportTo(f func(Event)) progress.Func {
+	return func(e progress.Event) {
+		f(Event{Kind: eventKinds[e.Kind], Node: e.Node, Path: e.Path, Msg: e.Msg})
+	}
+}
+
+// Archetype describes something Genesis can generate.
+type Archetype struct {
+	Name        string
+	Description string
+	Launch      string // command that starts the generated project
+	Options     []Option
+}
+
+// Option is one entry of an archetype's options schema.
+type Option struct {
+	Name    string
+	Kind    string // "bool", "string" or "int"
+	Default string
+	Usage   string
+}
+
+// Archetypes lists the built-in archetypes, sorted by name.
+func Archetypes() []Archetype {
+	var out []Archetype
+	for _, a := range archetype.All() {
+		out = append(out, describe(a))
+	}
+	return out
+}
+
+// Lookup describes the built-in archetype called name.
+func Lookup(name string) (Archetype, bool) {
+	a, ok := archetype.Lookup(name)
+	if !ok {
+		return Archetype{}, false
+	}
+	return describe(a), true
+}
+
+// LoadPack describes the template pack in dir.
+func LoadPack(dir string) (Archetype, error) {
+	pk, err := pack.Load(dir)
+	if err != nil {
+		return Archetype{}, err
+	}
+	return describe(pk), nil
+}
+
+func describe(a archetype.Archetype) Archetype {
+	d := Archetype{Name: a.Name(), Description: a.Description(), Launch: a.Launch()}
+	for _, o := range a.Options() {
+		d.Options = append(d.Options, Option{Name: o.Name, Kind: string(o.Kind), Default: o.Default, Usage: o.Usage})
+	}
+	return d
+}
+
+// ValidateName reports whether name can be used as a project name: as a
+// directory, an npm package, a Go module, a database and a container.
+func ValidateName(name string) error {
+	return archetype.ValidateName(name)
+}
+
+// Generate renders the project described by opts and writes it into fsys,
+// whose root becomes the project root. The output is generated and
+// validated in memory first, so fsys only ever receives a complete project.
+func Generate(ctx context.Context, opts Options, fsys FS) (Report, error) {
+	if err := archetype.ValidateName(opts.Name); err != nil {
+		return Report{}, err
+	}
+	arch, err := resolve(opts)
+	if err != nil {
+		return Report{}, err
+	}
+	values, err := archetype.Resolve(arch, opts.Values)
+	if err != nil {
+		return Report{}, err
+	}
+
+	// Builders address files by absolute path under the project root. The
+	// root is never created: the Writer maps it onto fsys.
+	root, err := filepath.Abs(filepath.Join(string(filepath.Separator), opts.Name))
+	if err != nil {
+		return Report{}, err
+	}
+	project := archetype.Project{Name: opts.Name, Root: root, Options: values}
+
+	plan, err := arch.Plan(project)
+	if err != nil {
+		return Report{}, fmt.Errorf("render: %w", err)
+	}
+	m := manifest.New(version.Version, arch.Name(), project.Name, project.Options, plan.Files)
+	if pk, ok := arch.(*pack.Pack); ok {
+		m.Template = pk.Dir
+	}
+	stamps, err := manifest.Stamp(&plan, m)
+	if err != nil {
+		return Report{}, fmt.Errorf("manifest: %w", err)
+	}
+
+	if opts.Progress != nil {
+		ctx = progress.With(ctx, reportTo(opts.Progress))
+	}
+	mem := vfs.NewMem()
+	w := render.NewWriter(root, mem, nil)
+	if err := arch.Generate(ctx, project, w); err != nil {
+		return Report{}, err
+	}
+	for _, f := range stamps {
+		if err := w.WriteFile(filepath.Join(root, f.Path), f.Content, f.Mode); err != nil {
+			return Report{}, fmt.Errorf("manifest: %w", err)
+		}
+	}
+	if err := stage.Verify(mem, plan, nil); err != nil {
+		return Report{}, fmt.Errorf("validate: %w", err)
+	}
+	if _, err := vfs.Copy(fsys, mem); err != nil {
+		return Report{}, fmt.Errorf("write: %w", err)
+	}
+
+	r := Report{Archetype: arch.Name(), Name: project.Name}
+	for _, name := range mem.Paths() {
+		info, err := fs.Stat(mem, name)
+		if err != nil {
+			return Report{}, err
+		}
+		r.Files = append(r.Files, File{Path: name, Size: int(info.Size()), Mode: info.Mode()})
+	}
+	sort.Slice(r.Files, func(i, j int) bool { return r.Files[i].Path < r.Files[j].Path })
+	for _, c := range plan.Commands {
+		r.Commands = append(r.Commands, Command{Hook: c.Hook, Dir: c.Dir, Name: c.Name, Args: c.Args, Network: c.Network, Timeout: hook.Timeout(c)})
+	}
+	return r, nil
+}
+
+// resolve finds the archetype or template pack opts asks for.
+func resolve(opts Options) (archetype.Archetype, error) {
+	if opts.Template != "" {
+		if opts.Archetype != "" {
+			return nil, errors.New("set Archetype or Template, not both")
+		}
+		return pack.Load(opts.Template)
+	}
+	name := opts.Archetype
+	if name == "" {
+		name = DefaultArchetype
+	}
+	a, ok := archetype.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown archetype: %q (have %s)", name, strings.Join(archetype.Names(), ", "))
+	}
+	return a, nil
+}
+
+// FS is where Generate writes a project. Names are slash-separated and
+// relative to the project root; parent directories are created as needed.
+type FS interface {
+	MkdirAll(name string, perm fs.FileMode) error
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// DirFS writes into the directory dir on disk, creating it as needed.
+func DirFS(dir string) FS {
+	return vfs.OS{Dir: dir}
+}
+
+// MemFS holds a project in memory. It is also an fs.FS, so the result can
+// be read back.
+type MemFS struct {
+	mem *vfs.Mem
+}
+
+// NewMemFS returns an empty in-memory FS.
+func NewMemFS() *MemFS {
+	return &MemFS{mem: vfs.NewMem()}
+}
+
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	return m.mem.MkdirAll(name, perm)
+}
+
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return m.mem.WriteFile(name, data, perm)
+}
+
+// Open implements fs.FS over what has been written so far.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	return m.mem.Open(name)
+}
+
+// Paths lists the files written, sorted.
+func (m *MemFS) Paths() []string {
+	return m.mem.Paths()
+}
+
+// Archive streams a project into a tar.gz or zip. Call Close when
+// Generate returns.
+type Archive struct {
+	a *vfs.Archive
+}
+
+// NewTarGz returns an Archive writing a gzip-compressed tar to w.
+func NewTarGz(w io.Writer) *Archive {
+	return &Archive{a: vfs.NewTarGz(w)}
+}
+
+// NewZip returns an Archive writing a zip to w.
+func NewZip(w io.Writer) *Archive {
+	return &Archive{a: vfs.NewZip(w)}
+}
+
+func (a *Archive) MkdirAll(name string, perm fs.FileMode) error {
+	return a.a.MkdirAll(name, perm)
+}
+
+func (a *Archive) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return a.a.WriteFile(name, data, perm)
+}
+
+// Close finishes the archive. It does not close the underlying writer.
+func (a *Archive) Close() error {
+	return a.a.Close()
+}
+
+// Sub returns an FS that writes into dir inside fsys, for example to put
+// a project under its own directory in an archive.
+func Sub(fsys FS, dir string) FS {
+	return vfs.Sub(fsys, dir)
+}
@@ -0,0 +1,160 @@
+package genesis_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/holodanger/genesis/pkg/genesis"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, a := range genesis.Archetypes() {
+		t.Run(a.Name, func(t *testing.T) {
+			var mu sync.Mutex
+			files := 0
+			opts := genesis.Options{
+				Name:      "portal-app",
+				Archetype: a.Name,
+				Values:    map[string]string{"ai": "true"},
+				Progress: func(e genesis.Event) {
+					mu.Lock()
+					defer mu.Unlock()
+					if e.Kind == genesis.EventFile {
+						files++
+					}
+				},
+			}
+			if !hasOption(a, "ai") {
+				delete(opts.Values, "ai")
+			}
+
+			mem := genesis.NewMemFS()
+			r, err := genesis.Generate(context.Background(), opts, mem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(r.Files) != len(mem.Paths()) {
+				t.Errorf("report lists %d files, FS holds %d", len(r.Files), len(mem.Paths()))
+			}
+			if files == 0 {
+				t.Error("no file progress reported")
+			}
+			if len(r.Commands) == 0 {
+				t.Error("no follow-up commands reported")
+			}
+			if _, err := fs.Stat(mem, ".genesis.json"); err != nil {
+				t.Errorf("manifest missing: %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateRejects(t *testing.T) {
+	cases := []genesis.Options{
+		{Name: "../escape"},
+		{Name: "app", Archetype: "rust"},
+		{Name: "app", Values: map[string]string{"web-port": "many"}},
+		{Name: "app", Values: map[string]string{"no-such-option": "1"}},
+		{Name: "app", Archetype: "go", Template: "."},
+	}
+	for _, opts := range cases {
+		mem := genesis.NewMemFS()
+		if _, err := genesis.Generate(context.Background(), opts, mem); err == nil {
+			t.Errorf("Generate(%+v) succeeded", opts)
+		}
+		if n := len(mem.Paths()); n != 0 {
+			t.Errorf("Generate(%+v) wrote %d files after failing", opts, n)
+		}
+	}
+}
+
+func TestGenerateReportsHookTimeouts(t *testing.T) {
+	r, err := genesis.Generate(context.Background(), genesis.Options{Name: "web", Archetype: "t3"}, genesis.NewMemFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range r.Commands {
+		if c.Hook == "install" {
+			if c.Timeout != 5*time.Minute {
+				t.Errorf("%s: timeout %v, want 5m", c, c.Timeout)
+			}
+			return
+		}
+	}
+	t.Errorf("no install command in %+v", r.Commands)
+}
+
+func TestGeneratePack(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"genesis-pack.yaml":        "name: svc\nfiles:\n  - README.md\ncommands:\n  - run: go mod tidy\n    hook: tidy\n    timeout: 90s\n",
+		"templates/README.md.tmpl": "# {{.Name}}\n",
+	} {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var kinds []string
+	var mu sync.Mutex
+	r, err := genesis.Generate(context.Background(), genesis.Options{
+		Name:     "svc",
+		Template: dir,
+		Progress: func(e genesis.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			kinds = append(kinds, e.Kind.String())
+		},
+	}, genesis.NewMemFS())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Commands) != 1 || r.Commands[0].Timeout != 90*time.Second {
+		t.Errorf("commands = %+v, want go mod tidy with its 90s timeout", r.Commands)
+	}
+	if !slices.Contains(kinds, "file") {
+		t.Errorf("events = %v, want file events", kinds)
+	}
+}
+
+func hasOption(a genesis.Archetype, name string) bool {
+	for _, o := range a.Options {
+		if o.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func ExampleGenerate() {
+	mem := genesis.NewMemFS()
+	r, err := genesis.Generate(context.Background(), genesis.Options{
+		Name:      "billing",
+		Archetype: "go",
+		Values:    map[string]string{"module": "example.com/billing"},
+	}, mem)
+	if err != nil {
+		panic(err)
+	}
+	gomod, _ := fs.ReadFile(mem, "go.mod")
+	fmt.Printf("%s", gomod[:len("module example.com/billing\n")])
+	for _, c := range r.Commands {
+		fmt.Printf("%s: %s\n", c.Hook, c)
+	}
+	// Output:
+	// module example.com/billing
+	// codegen: sqlc generate
+	// tidy: go mod tidy
+	// format: gofmt -w .
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/manifest"
+	"github.com/holodanger/genesis/internal/pack"
+	"github.com/holodanger/genesis/internal/render"
+	"github.com/holodanger/genesis/internal/version"
+)
+
+// stamp records the generation of plan by arch; see manifest.Stamp.
+func stamp(plan *render.Plan, arch archetype.Archetype, p archetype.Project) ([]render.File, error) {
+	m := manifest.New(version.Version, arch.Name(), p.Name, p.Options, plan.Files)
+	if pk, ok := arch.(*pack.Pack); ok {
+		m.Template = pk.Dir
+	}
+	return manifest.Stamp(plan, m)
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/holodanger/genesis/internal/archetype"
+	"github.com/holodanger/genesis/internal/spec"
+)
+
+// errWizardDeclined means the user looked at the preview and said no.
+var errWizardDeclined = errors.New("declined at preview")
+
+// wizard asks for everything `genesis new` would otherwise take from flags
+// and returns it as a spec, so the same path spawns the project.
+func wizard(in io.Reader, out io.Writer) (*spec.Spec, error) {
+	q := &questioner{in: bufio.NewReader(in), out: out}
+
+	fmt.Fprintln(out, "🧭 [WIZARD] No project name given. Answer a few questions (Enter takes the default).")
+	fmt.Fprintln(out, "   Scripts should pass -name or -f, or -no-input to fail instead of asking.")
+	fmt.Fprintln(out)
+
+	s := &spec.Spec{Version: 1}
+	var err error
+
+	// 1. IDENTITY
+	if s.Name, err = q.text("Project name", "", archetype.ValidateName); err != nil {
+		return nil, err
+	}
+
+	// 2. ARCHETYPE
+	var names, labels []string
+	for _, a := range archetype.All() {
+		names = append(names, a.Name())
+		labels = append(labels, fmt.Sprintf("%-8s %s", a.Name(), a.Description()))
+	}
+	if s.Archetype, err = q.choose("Archetype", names, labels, "t3"); err != nil {
+		return nil, err
+	}
+	arch, _ := archetype.Lookup(s.Archetype)
+
+	// 3. CAPABILITIES
+	declared := map[string]archetype.Option{}
+	for _, opt := range arch.Options() {
+		declared[opt.Name] = opt
+	}
+	if _, ok := declared["ai"]; ok {
+		if s.AI.Provider, err = q.choose("AI provider", spec.Choices("ai.provider"), nil, "none"); err != nil {
+			return nil, err
+		}
+	}
+	if opt, ok := declared["auth"]; ok {
+		if s.Auth.Providers, err = q.chooseMany("Sign-in methods", spec.Choices("auth.providers"), archetype.ParseAuth(opt.Default)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Anything else the archetype exposes is asked generically.
+	for _, opt := range arch.Options() {
+		if opt.Name == "ai" || opt.Name == "auth" {
+			continue
+		}
+		label := opt.Usage
+		// As in archetype.Resolve, an empty value means the default and is
+		// not checked.
+		check := func(v string) error {
+			if v == "" || opt.Check == nil {
+				return nil
+			}
+			return opt.Check(v)
+		}
+		var val string
+		switch opt.Kind {
+		case archetype.KindBool:
+			val, err = q.choose(label, []string{"true", "false"}, nil, opt.Default)
+		case archetype.KindInt:
+			val, err = q.text(label, opt.Default, func(v string) error {
+				if _, err := strconv.Atoi(v); err != nil {
+					return fmt.Errorf("%q is not a number", v)
+				}
+				return check(v)
+			})
+		default:
+			val, err = q.text(label, opt.Default, check)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if val == opt.Default {
+			continue
+		}
+		// Settings the spec has a field for go there, the rest to options.
+		switch opt.Name {
+		case "module":
+			s.Module = val
+		case "db-name":
+			s.Database.Name = val
+		case "web-port":
+			s.Ports.Web, _ = strconv.Atoi(val)
+		case "api-port":
+			s.Ports.API, _ = strconv.Atoi(val)
+		default:
+			if s.Options == nil {
+				s.Options = map[string]string{}
+			}
+			s.Options[opt.Name] = val
+		}
+	}
+
+	// 4. PREVIEW
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "📋 [PREVIEW]")
+	fmt.Fprintf(out, "   Name:      %s\n", s.Name)
+	fmt.Fprintf(out, "   Archetype: %s\n", s.Archetype)
+	if s.AI.Provider != "" {
+		fmt.Fprintf(out, "   AI:        %s\n", s.AI.Provider)
+	}
+	if len(s.Auth.Providers) > 0 {
+		fmt.Fprintf(out, "   Auth:      %s\n", strings.Join(s.Auth.Providers, ", "))
+	}
+	if s.Module != "" {
+		fmt.Fprintf(out, "   Module:    %s\n", s.Module)
+	}
+	if s.Database.Name != "" {
+		fmt.Fprintf(out, "   DB name:   %s\n", s.Database.Name)
+	}
+	if s.Ports.Web != 0 {
+		fmt.Fprintf(out, "   Web port:  %d\n", s.Ports.Web)
+	}
+	if s.Ports.API != 0 {
+		fmt.Fprintf(out, "   API port:  %d\n", s.Ports.API)
+	}
+	for _, k := range sortedKeys(s.Options) {
+		fmt.Fprintf(out, "   %-10s %s\n", k+":", s.Options[k])
+	}
+	ok, err := q.confirm("Spawn it?", true)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errWizardDeclined
+	}
+	fmt.Fprintf(out, "   (non-interactive: genesis new -name %s -type %s%s)\n", s.Name, s.Archetype, flagsFor(s))
+	return s, nil
+}
+
+// flagsFor renders the options of s as the equivalent flags, so a wizard
+// run can be repeated from a script.
+func flagsFor(s *spec.Spec) string {
+	var b strings.Builder
+	if s.AI.Provider == "openai" {
+		b.WriteString(" -ai")
+	}
+	if s.Module != "" {
+		fmt.Fprintf(&b, " -module %s", s.Module)
+	}
+	if auth := strings.Join(s.Auth.Providers, ","); auth != "" && auth != archetype.OptAuth.Default {
+		fmt.Fprintf(&b, " -opt auth=%s", auth)
+	}
+	if s.Database.Name != "" {
+		fmt.Fprintf(&b, " -opt db-name=%s", s.Database.Name)
+	}
+	if s.Ports.Web != 0 {
+		fmt.Fprintf(&b, " -opt web-port=%d", s.Ports.Web)
+	}
+	if s.Ports.API != 0 {
+		fmt.Fprintf(&b, " -opt api-port=%d", s.Ports.API)
+	}
+	for _, k := range sortedKeys(s.Options) {
+		fmt.Fprintf(&b, " -opt %s=%s", k, s.Options[k])
+	}
+	return b.String()
+}
+
+// questioner reads answers line by line.
+type questioner struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (q *questioner) line(prompt string) (string, error) {
+	fmt.Fprint(q.out, prompt)
+	answer, err := q.in.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		if err == io.EOF {
+			return "", errors.New("no answer (input closed); pass -name or -f, or use -no-input")
+		}
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// text asks for free-form input until check accepts it.
+func (q *questioner) text(label, def string, check func(string) error) (string, error) {
+	prompt := fmt.Sprintf("   %s: ", label)
+	if def != "" {
+		prompt = fmt.Sprintf("   %s [%s]: ", label, def)
+	}
+	for {
+		answer, err := q.line(prompt)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if check == nil {
+			return answer, nil
+		}
+		if err := check(answer); err != nil {
+			fmt.Fprintf(q.out, "   ⚠️  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// choose asks for one of choices, by number or by value. A single choice
+// is shown but not asked.
+func (q *questioner) choose(label string, choices, labels []string, def string) (string, error) {
+	if len(choices) == 1 {
+		fmt.Fprintf(q.out, "   %s: %s (only option)\n", label, choices[0])
+		return choices[0], nil
+	}
+	if labels == nil {
+		labels = choices
+	}
+	fmt.Fprintf(q.out, "   %s:\n", label)
+	for i, l := range labels {
+		fmt.Fprintf(q.out, "     %d) %s\n", i+1, l)
+	}
+	for {
+		answer, err := q.line(fmt.Sprintf("   Choose 1-%d [%s]: ", len(choices), def))
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return def, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		for _, c := range choices {
+			if strings.EqualFold(answer, c) {
+				return c, nil
+			}
+		}
+		fmt.Fprintf(q.out, "   ⚠️  pick one of %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// chooseMany asks for one or more of choices, comma-separated, each by
+// number or by value.
+func (q *questioner) chooseMany(label string, choices, def []string) ([]string, error) {
+	fmt.Fprintf(q.out, "   %s:\n", label)
+	for i, c := range choices {
+		fmt.Fprintf(q.out, "     %d) %s\n", i+1, c)
+	}
+next:
+	for {
+		answer, err := q.line(fmt.Sprintf("   Choose one or more, comma-separated [%s]: ", strings.Join(def, ",")))
+		if err != nil {
+			return nil, err
+		}
+		if answer == "" {
+			return def, nil
+		}
+		var picked []string
+		for _, a := range strings.Split(answer, ",") {
+			a = strings.TrimSpace(a)
+			if a == "" {
+				continue
+			}
+			c := ""
+			if n, err := strconv.Atoi(a); err == nil && n >= 1 && n <= len(choices) {
+				c = choices[n-1]
+			}
+			for _, choice := range choices {
+				if strings.EqualFold(a, choice) {
+					c = choice
+				}
+			}
+			if c == "" {
+				fmt.Fprintf(q.out, "   ⚠️  %q is not one of %s\n", a, strings.Join(choices, ", "))
+				continue next
+			}
+			if !slices.Contains(picked, c) {
+				picked = append(picked, c)
+			}
+		}
+		if len(picked) > 0 {
+			return picked, nil
+		}
+	}
+}
+
+func (q *questioner) confirm(label string, def bool) (bool, error) {
+	hint := "Y/n"
+	if !def {
+		hint = "y/N"
+	}
+	for {
+		answer, err := q.line(fmt.Sprintf("   %s [%s] ", label, hint))
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}